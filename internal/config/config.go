@@ -5,16 +5,30 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Minio    MinioConfig    `mapstructure:"minio"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	Minio     MinioConfig     `mapstructure:"minio"`
+	Storage   StorageConfig   `mapstructure:"storage"`
+	Upload    UploadConfig    `mapstructure:"upload"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	Counter   CounterConfig   `mapstructure:"counter"`
+	Search    SearchConfig    `mapstructure:"search"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Site      SiteConfig      `mapstructure:"site"`
+	RBAC      RBACConfig      `mapstructure:"rbac"`
+	Mail      MailConfig      `mapstructure:"mail"`
+	Account   AccountConfig   `mapstructure:"account"`
+	Password  PasswordConfig  `mapstructure:"password"`
+	Login     LoginConfig     `mapstructure:"login"`
+	OAuth     OAuthConfig     `mapstructure:"oauth"`
+	OIDC      OIDCConfig      `mapstructure:"oidc"`
 }
 
 type ServerConfig struct {
-	Port string `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Port           string   `mapstructure:"port"`
+	Mode           string   `mapstructure:"mode"`
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // 可信反向代理的IP或CIDR，为空时不信任X-Forwarded-For
 }
 
 type DatabaseConfig struct {
@@ -38,11 +52,126 @@ type MinioConfig struct {
 	SecretAccessKey string `mapstructure:"secret_access_key"`
 	UseSSL          bool   `mapstructure:"use_ssl"`
 	BucketName      string `mapstructure:"bucket_name"`
+	BaseURL         string `mapstructure:"base_url"` // 文件访问地址前缀，为空时使用endpoint/bucket_name
+}
+
+type StorageConfig struct {
+	Driver string             `mapstructure:"driver"` // minio(默认), local, memory
+	Local  LocalStorageConfig `mapstructure:"local"`
+}
+
+type UploadConfig struct {
+	Types []UploadTypeConfig `mapstructure:"types"` // 允许上传的文件类型，按文件内容检测，未列出的类型一律拒绝
+}
+
+type UploadTypeConfig struct {
+	MIME       string   `mapstructure:"mime"`       // MIME类型
+	Extensions []string `mapstructure:"extensions"` // 允许的扩展名，扩展名与内容不符时拒绝
+	MaxSize    int64    `mapstructure:"max_size"`   // 大小上限(MB)
+}
+
+type LocalStorageConfig struct {
+	Dir     string `mapstructure:"dir"`      // 存储根目录
+	BaseURL string `mapstructure:"base_url"` // 文件访问地址前缀，文件由本服务的/static路由提供
 }
 
 type JWTConfig struct {
-	Secret     string `mapstructure:"secret"`
-	ExpireHour int    `mapstructure:"expire_hour"`
+	Secret             string `mapstructure:"secret"`               // HMAC密钥，用于邮件链接等数据签名
+	Algorithm          string `mapstructure:"algorithm"`            // 新签名密钥的算法：RS256、ES256、EdDSA
+	KeyDir             string `mapstructure:"key_dir"`              // 签名密钥目录(PKCS#8 PEM)，多实例部署时需共享；为空时只保存在内存中
+	RotateIntervalHour int    `mapstructure:"rotate_interval_hour"` // 签名密钥轮换间隔(小时)，0表示不自动轮换
+	KeyRetentionHour   int    `mapstructure:"key_retention_hour"`   // 旧密钥被替换后继续用于验证的时间(小时)
+	AccessExpireMinute int    `mapstructure:"access_expire_minute"` // access token有效期(分钟)
+	RefreshExpireHour  int    `mapstructure:"refresh_expire_hour"`  // refresh token有效期(小时)，每次刷新重新计算
+}
+
+type CounterConfig struct {
+	FlushInterval int `mapstructure:"flush_interval"` // 计数回写数据库的间隔(秒)
+	ViewWindow    int `mapstructure:"view_window"`    // 同一访客重复浏览不计数的时间窗口(分钟)
+}
+
+type SearchConfig struct {
+	Engine string `mapstructure:"engine"` // mysql, memory
+}
+
+type SchedulerConfig struct {
+	PublishInterval int `mapstructure:"publish_interval"` // 定时发布检查间隔(秒)
+}
+
+type SiteConfig struct {
+	Title       string `mapstructure:"title"`
+	URL         string `mapstructure:"url"` // 站点前端地址，用于生成文章链接
+	Description string `mapstructure:"description"`
+}
+
+type RBACConfig struct {
+	Admins []string `mapstructure:"admins"` // 启动时授予管理员角色的用户名
+}
+
+type MailConfig struct {
+	Driver   string `mapstructure:"driver"` // smtp, log
+	From     string `mapstructure:"from"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Dir      string `mapstructure:"dir"` // log驱动下保存邮件的目录，为空时只输出日志
+}
+
+type AccountConfig struct {
+	RequireEmailVerification bool `mapstructure:"require_email_verification"` // 邮箱验证前禁止登录
+	VerifyTokenTTL           int  `mapstructure:"verify_token_ttl"`           // 邮箱验证链接有效期(分钟)
+	ResetTokenTTL            int  `mapstructure:"reset_token_ttl"`            // 密码重置链接有效期(分钟)
+	MailInterval             int  `mapstructure:"mail_interval"`              // 同一用户两次发送邮件的最小间隔(秒)
+}
+
+type PasswordConfig struct {
+	MinLength     int  `mapstructure:"min_length"`
+	RequireUpper  bool `mapstructure:"require_upper"`  // 必须包含大写字母
+	RequireLower  bool `mapstructure:"require_lower"`  // 必须包含小写字母
+	RequireDigit  bool `mapstructure:"require_digit"`  // 必须包含数字
+	RequireSymbol bool `mapstructure:"require_symbol"` // 必须包含特殊字符
+}
+
+type LoginConfig struct {
+	FreeAttempts       int `mapstructure:"free_attempts"`        // 不限制间隔的连续失败次数，超过后按指数退避
+	BackoffBase        int `mapstructure:"backoff_base"`         // 退避初始等待时间(秒)，之后每次失败翻倍
+	BackoffMax         int `mapstructure:"backoff_max"`          // 退避最长等待时间(秒)
+	MaxAccountFailures int `mapstructure:"max_account_failures"` // 单个账号连续失败达到该次数后锁定
+	MaxIPFailures      int `mapstructure:"max_ip_failures"`      // 单个IP连续失败达到该次数后锁定
+	LockoutMinute      int `mapstructure:"lockout_minute"`       // 锁定时长(分钟)
+	WindowMinute       int `mapstructure:"window_minute"`        // 失败次数统计窗口(分钟)，窗口内无失败时计数清零
+}
+
+type OAuthConfig struct {
+	AllowSignup bool                           `mapstructure:"allow_signup"` // 第三方账号无法关联本地账号时自动注册
+	Providers   map[string]OAuthProviderConfig `mapstructure:"providers"`    // 按提供方名称配置，支持github、google
+}
+
+type OAuthProviderConfig struct {
+	ClientID     string   `mapstructure:"client_id"` // 为空时不启用该提供方
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"` // 授权后回调的前端地址
+	Scopes       []string `mapstructure:"scopes"`
+	// 以下地址为空时使用提供方默认地址，可指向自建服务或本地测试服务
+	AuthURL     string `mapstructure:"auth_url"`
+	TokenURL    string `mapstructure:"token_url"`
+	UserInfoURL string `mapstructure:"userinfo_url"`
+}
+
+type OIDCConfig struct {
+	Issuer              string             `mapstructure:"issuer"`                 // 对外访问的API地址，作为ID token的iss
+	LoginURL            string             `mapstructure:"login_url"`              // 前端登录授权页面，授权请求携带原始参数跳转到该页面
+	CodeTTL             int                `mapstructure:"code_ttl"`               // 授权码有效期(秒)
+	IDTokenExpireMinute int                `mapstructure:"id_token_expire_minute"` // ID token和access token有效期(分钟)
+	Clients             []OIDCClientConfig `mapstructure:"clients"`
+}
+
+type OIDCClientConfig struct {
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"` // 为空表示公开客户端，必须使用PKCE
+	Name         string   `mapstructure:"name"`
+	RedirectURIs []string `mapstructure:"redirect_uris"` // 允许的回调地址，需完全匹配
 }
 
 func Load() (*Config, error) {
@@ -76,6 +205,7 @@ func setDefaults() {
 	// Server defaults
 	viper.SetDefault("server.port", "8080")
 	viper.SetDefault("server.mode", "debug")
+	viper.SetDefault("server.trusted_proxies", []string{})
 
 	// Database defaults
 	viper.SetDefault("database.host", "localhost")
@@ -97,7 +227,77 @@ func setDefaults() {
 	viper.SetDefault("minio.use_ssl", false)
 	viper.SetDefault("minio.bucket_name", "blog-files")
 
+	// Storage defaults
+	viper.SetDefault("storage.driver", "minio")
+	viper.SetDefault("storage.local.dir", "data/uploads")
+	viper.SetDefault("storage.local.base_url", "http://localhost:8868/static")
+
+	// Upload defaults
+	viper.SetDefault("upload.types", []map[string]interface{}{
+		{"mime": "image/jpeg", "extensions": []string{".jpg", ".jpeg"}, "max_size": 10},
+		{"mime": "image/png", "extensions": []string{".png"}, "max_size": 10},
+		{"mime": "image/gif", "extensions": []string{".gif"}, "max_size": 10},
+		{"mime": "image/webp", "extensions": []string{".webp"}, "max_size": 10},
+		{"mime": "application/pdf", "extensions": []string{".pdf"}, "max_size": 20},
+		{"mime": "text/plain", "extensions": []string{".txt", ".md"}, "max_size": 1},
+	})
+
 	// JWT defaults
-	viper.SetDefault("jwt.secret", "your-secret-key")
-	viper.SetDefault("jwt.expire_hour", 24)
+	viper.SetDefault("jwt.algorithm", "RS256")
+	viper.SetDefault("jwt.key_dir", "keys")
+	viper.SetDefault("jwt.rotate_interval_hour", 720)
+	viper.SetDefault("jwt.key_retention_hour", 24)
+	viper.SetDefault("jwt.access_expire_minute", 15)
+	viper.SetDefault("jwt.refresh_expire_hour", 720)
+
+	// Counter defaults
+	viper.SetDefault("counter.flush_interval", 60)
+	viper.SetDefault("counter.view_window", 30)
+
+	// Search defaults
+	viper.SetDefault("search.engine", "mysql")
+
+	// Scheduler defaults
+	viper.SetDefault("scheduler.publish_interval", 30)
+
+	// Site defaults
+	viper.SetDefault("site.title", "Blog")
+	viper.SetDefault("site.url", "http://localhost:8868")
+	viper.SetDefault("site.description", "博客系统")
+
+	// Mail defaults
+	viper.SetDefault("mail.driver", "log")
+	viper.SetDefault("mail.from", "Blog <noreply@localhost>")
+	viper.SetDefault("mail.port", 587)
+
+	// Account defaults
+	viper.SetDefault("account.require_email_verification", false)
+	viper.SetDefault("account.verify_token_ttl", 1440)
+	viper.SetDefault("account.reset_token_ttl", 30)
+	viper.SetDefault("account.mail_interval", 60)
+
+	// Password policy defaults
+	viper.SetDefault("password.min_length", 6)
+	viper.SetDefault("password.require_upper", false)
+	viper.SetDefault("password.require_lower", false)
+	viper.SetDefault("password.require_digit", false)
+	viper.SetDefault("password.require_symbol", false)
+
+	// Login protection defaults
+	viper.SetDefault("login.free_attempts", 3)
+	viper.SetDefault("login.backoff_base", 1)
+	viper.SetDefault("login.backoff_max", 300)
+	viper.SetDefault("login.max_account_failures", 10)
+	viper.SetDefault("login.max_ip_failures", 50)
+	viper.SetDefault("login.lockout_minute", 15)
+	viper.SetDefault("login.window_minute", 15)
+
+	// OAuth defaults
+	viper.SetDefault("oauth.allow_signup", true)
+
+	// OIDC defaults
+	viper.SetDefault("oidc.issuer", "http://localhost:8868")
+	viper.SetDefault("oidc.login_url", "http://localhost:3000/oidc/authorize")
+	viper.SetDefault("oidc.code_ttl", 60)
+	viper.SetDefault("oidc.id_token_expire_minute", 60)
 }
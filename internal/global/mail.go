@@ -0,0 +1,6 @@
+package global
+
+import "blog/internal/mail"
+
+// Mailer 全局邮件发送变量
+var Mailer mail.Mailer
@@ -0,0 +1,6 @@
+package global
+
+import "blog/internal/oauth"
+
+// OAuthProviders 已启用的第三方登录提供方，按名称索引
+var OAuthProviders = map[string]oauth.Provider{}
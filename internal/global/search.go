@@ -0,0 +1,6 @@
+package global
+
+import "blog/internal/search"
+
+// Searcher 全局全文检索变量
+var Searcher search.Searcher
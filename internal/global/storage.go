@@ -0,0 +1,6 @@
+package global
+
+import "blog/internal/storage"
+
+// Storage 全局文件存储变量
+var Storage storage.Storage
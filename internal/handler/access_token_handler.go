@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"blog/internal/middleware"
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessTokenHandler struct {
+	accessTokenService *service.AccessTokenService
+}
+
+func NewAccessTokenHandler(accessTokenService *service.AccessTokenService) *AccessTokenHandler {
+	return &AccessTokenHandler{
+		accessTokenService: accessTokenService,
+	}
+}
+
+// CreateAccessToken 创建访问令牌
+// @Summary 创建访问令牌
+// @Description 创建供脚本和CI使用的个人访问令牌，授权范围为权限标识(如article:write、file:upload)且不能超出当前角色的权限；完整令牌仅在创建时返回一次，使用方式与JWT相同(Authorization: Bearer <token>)。通过邮件重置密码时所有访问令牌被删除；修改密码时默认保留，可选择一并删除
+// @Tags 访问令牌
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body req.AccessTokenCreateRequest true "令牌名称、授权范围和过期时间"
+// @Success 200 {object} resp.AccessTokenCreateResponse "创建成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "不支持使用访问令牌"
+// @Router /api/v1/tokens [post]
+func (h *AccessTokenHandler) CreateAccessToken(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	var request req.AccessTokenCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	token, err := utils.WithTransactionResult(c, func(ctx context.Context) (*resp.AccessTokenCreateResponse, error) {
+		return h.accessTokenService.Create(ctx, userID, &request)
+	})
+	if err != nil {
+		utils.Error(c, 1026, err.Error())
+		return
+	}
+
+	utils.Success(c, token)
+}
+
+// GetAccessTokens 获取访问令牌列表
+// @Summary 获取访问令牌列表
+// @Description 获取当前用户的个人访问令牌，包含名称、令牌前缀、授权范围、过期时间和最近使用时间。重置密码后所有访问令牌被删除
+// @Tags 访问令牌
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} resp.AccessTokenResponse "获取成功"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "不支持使用访问令牌"
+// @Router /api/v1/tokens [get]
+func (h *AccessTokenHandler) GetAccessTokens(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	tokens, err := h.accessTokenService.GetAccessTokens(c.Request.Context(), userID)
+	if err != nil {
+		utils.Error(c, 1027, "获取访问令牌列表失败")
+		return
+	}
+
+	utils.Success(c, tokens)
+}
+
+// RevokeAccessToken 删除访问令牌
+// @Summary 删除访问令牌
+// @Description 删除当前用户的指定访问令牌，令牌立即失效
+// @Tags 访问令牌
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "令牌ID"
+// @Success 200 {object} utils.Response "删除成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "不支持使用访问令牌"
+// @Router /api/v1/tokens/{id} [delete]
+func (h *AccessTokenHandler) RevokeAccessToken(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的令牌ID")
+		return
+	}
+
+	if err := h.accessTokenService.Revoke(c.Request.Context(), userID, uint(tokenID)); err != nil {
+		utils.Error(c, 1028, err.Error())
+		return
+	}
+
+	utils.Success(c, "删除成功")
+}
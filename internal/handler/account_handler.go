@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// VerifyEmail 验证邮箱
+// @Summary 验证邮箱
+// @Description 使用验证邮件中的令牌完成邮箱验证，令牌只能使用一次
+// @Tags 账号安全
+// @Accept json
+// @Produce json
+// @Param request body req.VerifyEmailRequest true "验证令牌"
+// @Success 200 {object} utils.Response "验证成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Router /api/v1/verify-email [post]
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var request req.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	err := utils.WithTransaction(c, func(ctx context.Context) error {
+		return h.accountService.VerifyEmail(ctx, &request)
+	})
+	if err != nil {
+		utils.Error(c, 1015, err.Error())
+		return
+	}
+
+	utils.Success(c, "邮箱验证成功")
+}
+
+// ResendVerification 重新发送验证邮件
+// @Summary 重新发送验证邮件
+// @Description 向未验证的邮箱重新发送验证邮件，旧的验证链接同时失效
+// @Tags 账号安全
+// @Accept json
+// @Produce json
+// @Param request body req.EmailRequest true "邮箱"
+// @Success 200 {object} utils.Response "发送成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Router /api/v1/resend-verification [post]
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	var request req.EmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.accountService.ResendVerification(c.Request.Context(), &request); err != nil {
+		utils.Error(c, 1016, "发送验证邮件失败")
+		return
+	}
+
+	utils.Success(c, "如果该邮箱已注册且未验证，验证邮件已发送")
+}
+
+// ForgotPassword 忘记密码
+// @Summary 忘记密码
+// @Description 向注册邮箱发送密码重置邮件，旧的重置链接同时失效
+// @Tags 账号安全
+// @Accept json
+// @Produce json
+// @Param request body req.EmailRequest true "邮箱"
+// @Success 200 {object} utils.Response "发送成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Router /api/v1/forgot-password [post]
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var request req.EmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.accountService.ForgotPassword(c.Request.Context(), &request); err != nil {
+		utils.Error(c, 1017, "发送重置邮件失败")
+		return
+	}
+
+	utils.Success(c, "如果该邮箱已注册，重置邮件已发送")
+}
+
+// ResetPassword 重置密码
+// @Summary 重置密码
+// @Description 使用重置邮件中的令牌设置新密码，令牌只能使用一次，重置后所有已登录设备需要重新登录，所有个人访问令牌被删除
+// @Tags 账号安全
+// @Accept json
+// @Produce json
+// @Param request body req.ResetPasswordRequest true "重置令牌和新密码"
+// @Success 200 {object} utils.Response "重置成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Router /api/v1/reset-password [post]
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var request req.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	err := utils.WithTransaction(c, func(ctx context.Context) error {
+		return h.accountService.ResetPassword(ctx, &request)
+	})
+	if err != nil {
+		utils.Error(c, 1018, err.Error())
+		return
+	}
+
+	utils.Success(c, "密码重置成功")
+}
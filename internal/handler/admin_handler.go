@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"blog/internal/middleware"
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/entity"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	userService *service.UserService
+}
+
+func NewAdminHandler(userService *service.UserService) *AdminHandler {
+	return &AdminHandler{
+		userService: userService,
+	}
+}
+
+// UpdateUserRole 修改用户角色
+// @Summary 修改用户角色
+// @Description 管理员修改指定用户的角色(admin、editor、author、reader)，不能修改自己的角色
+// @Tags 后台管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body req.UserRoleUpdateRequest true "角色"
+// @Success 200 {object} resp.UserProfileResponse "修改成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/admin/users/{id}/role [put]
+func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
+	operatorID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	var request req.UserRoleUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	user, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.User, error) {
+		return h.userService.UpdateUserRole(ctx, operatorID, uint(userID), request.Role)
+	})
+	if err != nil {
+		utils.Error(c, 1011, err.Error())
+		return
+	}
+
+	utils.Success(c, resp.ToUserProfileResponse(user))
+}
+
+// DisableUser 禁用用户
+// @Summary 禁用用户
+// @Description 管理员禁用指定用户，用户的所有token立即失效且无法再登录
+// @Tags 后台管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body req.UserDisableRequest false "禁用原因"
+// @Success 200 {object} entity.User "禁用成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/admin/users/{id}/disable [post]
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+	operatorID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	var request req.UserDisableRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.BadRequest(c, "参数错误: "+err.Error())
+			return
+		}
+	}
+
+	// 使用统一事务处理
+	user, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.User, error) {
+		return h.userService.DisableUser(ctx, operatorID, uint(userID), &request)
+	})
+	if err != nil {
+		utils.Error(c, 1012, err.Error())
+		return
+	}
+
+	utils.Success(c, user)
+}
+
+// SuspendUser 暂停用户
+// @Summary 暂停用户
+// @Description 管理员暂停指定用户至指定时间，未指定截止时间时无限期暂停，用户的所有token立即失效
+// @Tags 后台管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body req.UserSuspendRequest false "暂停截止时间和原因"
+// @Success 200 {object} entity.User "暂停成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/admin/users/{id}/suspend [post]
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	operatorID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	var request req.UserSuspendRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.BadRequest(c, "参数错误: "+err.Error())
+			return
+		}
+	}
+
+	// 使用统一事务处理
+	user, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.User, error) {
+		return h.userService.SuspendUser(ctx, operatorID, uint(userID), &request)
+	})
+	if err != nil {
+		utils.Error(c, 1013, err.Error())
+		return
+	}
+
+	utils.Success(c, user)
+}
+
+// EnableUser 恢复用户
+// @Summary 恢复用户
+// @Description 管理员将被禁用或暂停的用户恢复为正常状态
+// @Tags 后台管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} entity.User "恢复成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/admin/users/{id}/enable [post]
+func (h *AdminHandler) EnableUser(c *gin.Context) {
+	operatorID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	// 使用统一事务处理
+	user, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.User, error) {
+		return h.userService.EnableUser(ctx, operatorID, uint(userID))
+	})
+	if err != nil {
+		utils.Error(c, 1014, err.Error())
+		return
+	}
+
+	utils.Success(c, user)
+}
+
+// UnlockLogin 解除登录锁定
+// @Summary 解除登录锁定
+// @Description 管理员清除指定用户因连续登录失败产生的退避等待和临时锁定，IP维度的限制不受影响
+// @Tags 后台管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} utils.Response "解除成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *AdminHandler) UnlockLogin(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	if err := h.userService.UnlockLogin(c.Request.Context(), uint(userID)); err != nil {
+		utils.Error(c, 1025, err.Error())
+		return
+	}
+
+	utils.Success(c, "登录锁定已解除")
+}
@@ -2,7 +2,13 @@ package handler
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
 	"strconv"
+	"strings"
 
 	"blog/internal/middleware"
 	"blog/internal/service"
@@ -34,6 +40,7 @@ func NewArticleHandler(articleService *service.ArticleService) *ArticleHandler {
 // @Success 200 {object} entity.Article "创建成功"
 // @Failure 400 {object} utils.Response "参数错误"
 // @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
 // @Router /api/v1/articles [post]
 func (h *ArticleHandler) CreateArticle(c *gin.Context) {
 	userID, exists := middleware.GetCurrentUserID(c)
@@ -63,6 +70,7 @@ func (h *ArticleHandler) CreateArticle(c *gin.Context) {
 // GetArticles 获取文章列表
 // @Summary 获取文章列表
 // @Description 分页获取文章列表，支持搜索和筛选
+// @Security BearerAuth
 // @Tags 文章管理
 // @Accept json
 // @Produce json
@@ -70,7 +78,10 @@ func (h *ArticleHandler) CreateArticle(c *gin.Context) {
 // @Param page_size query int false "每页数量" default(10)
 // @Param keyword query string false "搜索关键词"
 // @Param author_id query int false "作者ID筛选"
-// @Param status query int false "文章状态" Enums(0, 1) default(1)
+// @Param tag query string false "标签名称筛选"
+// @Param category query string false "分类名称筛选"
+// @Param status query int false "文章状态，草稿和定时发布仅作者本人可查询" Enums(0, 1, 2) default(1)
+// @Param format query string false "正文格式，html时返回content_html、toc和reading_minutes" Enums(raw, html) default(raw)
 // @Success 200 {object} resp.ArticleListResponse "获取成功"
 // @Router /api/v1/articles [get]
 func (h *ArticleHandler) GetArticles(c *gin.Context) {
@@ -87,22 +98,71 @@ func (h *ArticleHandler) GetArticles(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.articleService.GetArticles(c.Request.Context(), &request)
+	// 未登录时viewerID为0，只能查看已发布文章
+	viewerID, _ := middleware.GetCurrentUserID(c)
+
+	resp, err := h.articleService.GetArticles(c.Request.Context(), viewerID, &request)
 	if err != nil {
 		utils.Error(c, 2002, err.Error())
 		return
 	}
 
+	if request.Format == "html" {
+		articles := make([]*entity.Article, 0, len(resp.Articles))
+		for i := range resp.Articles {
+			articles = append(articles, &resp.Articles[i])
+		}
+		if err := h.articleService.RenderArticles(c.Request.Context(), articles...); err != nil {
+			utils.Error(c, 2009, "渲染文章失败")
+			return
+		}
+	}
+
+	utils.Success(c, resp)
+}
+
+// SearchArticles 全文检索文章
+// @Summary 全文检索文章
+// @Description 按相关度检索已发布文章，返回高亮标题和摘要片段
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Param keyword query string true "搜索关键词"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} resp.ArticleSearchResponse "检索成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Router /api/v1/search [get]
+func (h *ArticleHandler) SearchArticles(c *gin.Context) {
+	var request req.ArticleSearchRequest
+
+	// 设置默认值
+	request.Page = 1
+	request.PageSize = 10
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.articleService.SearchArticles(c.Request.Context(), &request)
+	if err != nil {
+		utils.Error(c, 2008, err.Error())
+		return
+	}
+
 	utils.Success(c, resp)
 }
 
 // GetArticle 获取单篇文章
 // @Summary 获取文章详情
-// @Description 根据ID获取文章详细信息
+// @Description 根据ID获取文章详细信息，草稿和定时发布的文章仅作者本人可见
+// @Security BearerAuth
 // @Tags 文章管理
 // @Accept json
 // @Produce json
 // @Param id path int true "文章ID"
+// @Param format query string false "正文格式，html时返回content_html、toc和reading_minutes" Enums(raw, html) default(raw)
 // @Success 200 {object} entity.Article "获取成功"
 // @Failure 404 {object} utils.Response "文章不存在"
 // @Router /api/v1/articles/{id} [get]
@@ -114,15 +174,117 @@ func (h *ArticleHandler) GetArticle(c *gin.Context) {
 		return
 	}
 
-	article, err := h.articleService.GetArticleByID(c.Request.Context(), uint(id))
+	var request req.ArticleFormatRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	viewerID, _ := middleware.GetCurrentUserID(c)
+
+	article, err := h.articleService.GetArticleByID(c.Request.Context(), uint(id), viewerID)
 	if err != nil {
 		utils.Error(c, 2003, err.Error())
 		return
 	}
 
+	// 记录浏览量，失败不影响文章获取
+	if article.Status == 1 && !isBotUserAgent(c.Request.UserAgent()) {
+		if err := h.articleService.RecordView(c.Request.Context(), article.ID, visitorID(c)); err != nil {
+			log.Printf("Failed to record view for article %d: %v", article.ID, err)
+		}
+	}
+
+	if request.Format == "html" {
+		if err := h.articleService.RenderArticles(c.Request.Context(), article); err != nil {
+			utils.Error(c, 2009, "渲染文章失败")
+			return
+		}
+	}
+
+	utils.Success(c, article)
+}
+
+// GetArticleBySlug 根据slug获取文章
+// @Summary 根据slug获取文章详情
+// @Description 根据slug获取文章详细信息，使用历史slug访问时301跳转到当前slug
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "文章slug"
+// @Param format query string false "正文格式，html时返回content_html、toc和reading_minutes" Enums(raw, html) default(raw)
+// @Success 200 {object} entity.Article "获取成功"
+// @Success 301 {string} string "跳转到当前slug"
+// @Failure 404 {object} utils.Response "文章不存在"
+// @Router /api/v1/articles/slug/{slug} [get]
+func (h *ArticleHandler) GetArticleBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	var request req.ArticleFormatRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	viewerID, _ := middleware.GetCurrentUserID(c)
+
+	article, err := h.articleService.GetArticleBySlug(c.Request.Context(), slug, viewerID)
+	if err != nil {
+		utils.Error(c, 2003, err.Error())
+		return
+	}
+
+	// 旧slug跳转到当前slug
+	if article.Slug != slug {
+		location := "/api/v1/articles/slug/" + article.Slug
+		if c.Request.URL.RawQuery != "" {
+			location += "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(http.StatusMovedPermanently, location)
+		return
+	}
+
+	// 记录浏览量，失败不影响文章获取
+	if article.Status == 1 && !isBotUserAgent(c.Request.UserAgent()) {
+		if err := h.articleService.RecordView(c.Request.Context(), article.ID, visitorID(c)); err != nil {
+			log.Printf("Failed to record view for article %d: %v", article.ID, err)
+		}
+	}
+
+	if request.Format == "html" {
+		if err := h.articleService.RenderArticles(c.Request.Context(), article); err != nil {
+			utils.Error(c, 2009, "渲染文章失败")
+			return
+		}
+	}
+
 	utils.Success(c, article)
 }
 
+// visitorID 生成访客标识，已登录用户使用用户ID，匿名访客使用IP与UA的指纹
+func visitorID(c *gin.Context) string {
+	if userID, exists := middleware.GetCurrentUserID(c); exists {
+		return fmt.Sprintf("u%d", userID)
+	}
+	sum := sha1.Sum([]byte(c.ClientIP() + "|" + c.Request.UserAgent()))
+	return "v" + hex.EncodeToString(sum[:])
+}
+
+// isBotUserAgent 判断是否为爬虫等自动化访问
+func isBotUserAgent(ua string) bool {
+	if ua == "" {
+		return true
+	}
+	ua = strings.ToLower(ua)
+	for _, keyword := range []string{"bot", "spider", "crawler", "curl", "wget", "python-requests"} {
+		if strings.Contains(ua, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
 // UpdateArticle 更新文章
 // @Summary 更新文章
 // @Description 更新文章信息
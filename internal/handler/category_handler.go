@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/entity"
+	"blog/model/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	categoryService *service.CategoryService
+}
+
+func NewCategoryHandler(categoryService *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// GetCategories 获取分类列表
+// @Summary 获取分类列表
+// @Description 获取所有分类及其已发布文章数
+// @Tags 分类管理
+// @Accept json
+// @Produce json
+// @Success 200 {array} resp.CategoryResponse "获取成功"
+// @Router /api/v1/categories [get]
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	tags, err := h.categoryService.GetCategories(c.Request.Context())
+	if err != nil {
+		utils.Error(c, 6001, err.Error())
+		return
+	}
+
+	utils.Success(c, tags)
+}
+
+// CreateCategory 创建分类
+// @Summary 创建分类
+// @Description 创建新分类
+// @Tags 分类管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body req.CategoryCreateRequest true "分类信息"
+// @Success 200 {object} entity.Category "创建成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/categories [post]
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var request req.CategoryCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	tag, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.Category, error) {
+		return h.categoryService.CreateCategory(ctx, &request)
+	})
+	if err != nil {
+		utils.Error(c, 6002, err.Error())
+		return
+	}
+
+	utils.Success(c, tag)
+}
+
+// UpdateCategory 更新分类
+// @Summary 更新分类
+// @Description 修改分类名称或描述
+// @Tags 分类管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "分类ID"
+// @Param request body req.CategoryUpdateRequest true "分类信息"
+// @Success 200 {object} entity.Category "更新成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/categories/{id} [put]
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的分类ID")
+		return
+	}
+
+	var request req.CategoryUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	tag, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.Category, error) {
+		return h.categoryService.UpdateCategory(ctx, uint(id), &request)
+	})
+	if err != nil {
+		utils.Error(c, 6003, err.Error())
+		return
+	}
+
+	utils.Success(c, tag)
+}
+
+// MergeCategory 合并分类
+// @Summary 合并分类
+// @Description 将分类合并到目标分类，原分类下的文章移动到目标分类
+// @Tags 分类管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "被合并的分类ID"
+// @Param request body req.CategoryMergeRequest true "目标分类"
+// @Success 200 {object} entity.Category "合并成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/categories/{id}/merge [post]
+func (h *CategoryHandler) MergeCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的分类ID")
+		return
+	}
+
+	var request req.CategoryMergeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	tag, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.Category, error) {
+		return h.categoryService.MergeCategory(ctx, uint(id), &request)
+	})
+	if err != nil {
+		utils.Error(c, 6004, err.Error())
+		return
+	}
+
+	utils.Success(c, tag)
+}
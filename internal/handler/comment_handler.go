@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"blog/internal/middleware"
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/entity"
+	"blog/model/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentHandler struct {
+	commentService *service.CommentService
+}
+
+func NewCommentHandler(commentService *service.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+// GetComments 获取文章评论列表
+// @Summary 获取文章评论列表
+// @Description 按顶级评论分页获取文章评论，回复以嵌套形式返回
+// @Tags 评论管理
+// @Accept json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} resp.CommentListResponse "获取成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Router /api/v1/articles/{id}/comments [get]
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	var request req.CommentListRequest
+
+	// 设置默认值
+	request.Page = 1
+	request.PageSize = 10
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.commentService.GetComments(c.Request.Context(), uint(articleID), &request)
+	if err != nil {
+		utils.Error(c, 4001, err.Error())
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// CreateComment 发表评论
+// @Summary 发表评论
+// @Description 对文章发表评论，指定parent_id时为回复评论
+// @Tags 评论管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Param request body req.CommentCreateRequest true "评论信息"
+// @Success 200 {object} entity.Comment "发表成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/articles/{id}/comments [post]
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	var request req.CommentCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	comment, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.Comment, error) {
+		return h.commentService.CreateComment(ctx, uint(articleID), userID, &request)
+	})
+	if err != nil {
+		utils.Error(c, 4002, err.Error())
+		return
+	}
+
+	utils.Success(c, comment)
+}
+
+// UpdateComment 编辑评论
+// @Summary 编辑评论
+// @Description 编辑自己发表的评论
+// @Tags 评论管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Param comment_id path int true "评论ID"
+// @Param request body req.CommentUpdateRequest true "评论内容"
+// @Success 200 {object} entity.Comment "编辑成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/articles/{id}/comments/{comment_id} [put]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的评论ID")
+		return
+	}
+
+	var request req.CommentUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	comment, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.Comment, error) {
+		return h.commentService.UpdateComment(ctx, uint(articleID), uint(commentID), userID, &request)
+	})
+	if err != nil {
+		utils.Error(c, 4003, err.Error())
+		return
+	}
+
+	utils.Success(c, comment)
+}
+
+// DeleteComment 删除评论
+// @Summary 删除评论
+// @Description 删除评论及其所有回复，评论作者或文章作者可操作
+// @Tags 评论管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Param comment_id path int true "评论ID"
+// @Success 200 {object} utils.Response "删除成功"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/articles/{id}/comments/{comment_id} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的评论ID")
+		return
+	}
+
+	// 使用统一事务处理
+	err = utils.WithTransaction(c, func(ctx context.Context) error {
+		return h.commentService.DeleteComment(ctx, uint(articleID), uint(commentID), userID)
+	})
+	if err != nil {
+		utils.Error(c, 4004, err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
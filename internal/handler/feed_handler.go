@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeedHandler struct {
+	feedService *service.FeedService
+}
+
+func NewFeedHandler(feedService *service.FeedService) *FeedHandler {
+	return &FeedHandler{
+		feedService: feedService,
+	}
+}
+
+// RSS RSS 2.0订阅源
+// @Summary RSS订阅源
+// @Description 获取最新已发布文章的RSS 2.0订阅源，支持按作者和标签筛选，支持ETag/Last-Modified条件请求
+// @Tags 订阅源
+// @Produce xml
+// @Param author_id query int false "作者ID"
+// @Param tag query string false "标签名称"
+// @Success 200 {string} string "RSS文档"
+// @Success 304 {string} string "未修改"
+// @Router /feed.xml [get]
+func (h *FeedHandler) RSS(c *gin.Context) {
+	h.serveFeed(c, "application/rss+xml; charset=utf-8", func(feed *resp.Feed) ([]byte, error) {
+		return marshalXML(resp.ToRSS(feed))
+	})
+}
+
+// Atom Atom订阅源
+// @Summary Atom订阅源
+// @Description 获取最新已发布文章的Atom订阅源，支持按作者和标签筛选，支持ETag/Last-Modified条件请求
+// @Tags 订阅源
+// @Produce xml
+// @Param author_id query int false "作者ID"
+// @Param tag query string false "标签名称"
+// @Success 200 {string} string "Atom文档"
+// @Success 304 {string} string "未修改"
+// @Router /atom.xml [get]
+func (h *FeedHandler) Atom(c *gin.Context) {
+	h.serveFeed(c, "application/atom+xml; charset=utf-8", func(feed *resp.Feed) ([]byte, error) {
+		return marshalXML(resp.ToAtom(feed))
+	})
+}
+
+// JSONFeed JSON Feed订阅源
+// @Summary JSON Feed订阅源
+// @Description 获取最新已发布文章的JSON Feed订阅源，支持按作者和标签筛选，支持ETag/Last-Modified条件请求
+// @Tags 订阅源
+// @Produce json
+// @Param author_id query int false "作者ID"
+// @Param tag query string false "标签名称"
+// @Success 200 {object} resp.JSONFeed "JSON Feed文档"
+// @Success 304 {string} string "未修改"
+// @Router /feed.json [get]
+func (h *FeedHandler) JSONFeed(c *gin.Context) {
+	h.serveFeed(c, "application/feed+json; charset=utf-8", func(feed *resp.Feed) ([]byte, error) {
+		return json.Marshal(resp.ToJSONFeed(feed))
+	})
+}
+
+// serveFeed 生成订阅源并处理条件请求
+func (h *FeedHandler) serveFeed(c *gin.Context, contentType string, render func(feed *resp.Feed) ([]byte, error)) {
+	var request req.FeedRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	feed, err := h.feedService.GetFeed(c.Request.Context(), c.Request.URL.RequestURI(), &request)
+	if err != nil {
+		if errors.Is(err, service.ErrFeedAuthorNotFound) {
+			utils.NotFound(c, err.Error())
+			return
+		}
+		log.Printf("Get feed failed: %v", err)
+		utils.InternalServerError(c, "获取订阅源失败")
+		return
+	}
+
+	// ETag由订阅源地址、更新时间和文章列表决定
+	hash := sha1.New()
+	fmt.Fprintf(hash, "%s|%d", c.Request.URL.RequestURI(), feed.Updated.UnixNano())
+	for _, item := range feed.Items {
+		fmt.Fprintf(hash, "|%d:%d", item.ID, item.UpdatedAt.UnixNano())
+	}
+	etag := `W/"` + hex.EncodeToString(hash.Sum(nil)) + `"`
+	lastModified := feed.Updated.UTC().Truncate(time.Second)
+
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", "public, max-age=300")
+
+	if notModified(c, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	body, err := render(feed)
+	if err != nil {
+		utils.InternalServerError(c, "生成订阅源失败")
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// notModified 判断条件请求是否命中，If-None-Match优先于If-Modified-Since
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		return match == etag || match == "*"
+	}
+	if since := c.GetHeader("If-Modified-Since"); since != "" {
+		t, err := http.ParseTime(since)
+		return err == nil && !lastModified.After(t)
+	}
+	return false
+}
+
+// marshalXML 生成带XML声明的文档
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
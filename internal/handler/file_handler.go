@@ -2,15 +2,23 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
 
 	"blog/internal/middleware"
 	"blog/internal/service"
 	"blog/internal/utils"
+	"blog/model/req"
 	"blog/model/resp"
 
 	"github.com/gin-gonic/gin"
 )
 
+// multipartOverhead multipart请求中文件内容以外的部分(边界、表单头等)允许的大小
+const multipartOverhead = 1 << 20
+
 type FileHandler struct {
 	fileService *service.FileService
 }
@@ -23,7 +31,7 @@ func NewFileHandler(fileService *service.FileService) *FileHandler {
 
 // Upload 上传文件
 // @Summary 上传文件
-// @Description 上传文件到服务器
+// @Description 上传文件到服务器。文件类型按内容(魔数)检测而不是扩展名，只允许配置(upload.types)中列出的类型，扩展名须与内容一致，大小不能超过该类型的上限
 // @Tags 文件管理
 // @Accept multipart/form-data
 // @Produce json
@@ -32,6 +40,7 @@ func NewFileHandler(fileService *service.FileService) *FileHandler {
 // @Success 200 {object} resp.FileUploadResponse "上传成功"
 // @Failure 400 {object} utils.Response "文件格式错误或文件过大"
 // @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
 // @Router /api/v1/upload [post]
 func (h *FileHandler) Upload(c *gin.Context) {
 	userID, exists := middleware.GetCurrentUserID(c)
@@ -40,19 +49,22 @@ func (h *FileHandler) Upload(c *gin.Context) {
 		return
 	}
 
+	// 限制请求体大小，避免超大文件写入临时目录；各类型的大小上限由服务层校验
+	maxSize := h.fileService.MaxUploadSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
 	// 获取上传的文件
 	file, err := c.FormFile("file")
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.BadRequest(c, fmt.Sprintf("文件大小不能超过%dMB", maxSize/1024/1024))
+			return
+		}
 		utils.BadRequest(c, "请选择要上传的文件")
 		return
 	}
 
-	// 检查文件大小（限制为10MB）
-	if file.Size > 10*1024*1024 {
-		utils.BadRequest(c, "文件大小不能超过10MB")
-		return
-	}
-
 	// 使用统一事务处理上传文件
 	resp, err := utils.WithTransactionResult(c, func(ctx context.Context) (*resp.FileUploadResponse, error) {
 		return h.fileService.UploadFile(ctx, userID, file)
@@ -64,3 +76,199 @@ func (h *FileHandler) Upload(c *gin.Context) {
 
 	utils.Success(c, resp)
 }
+
+// GetMyFiles 获取我的文件列表
+// @Summary 获取我的文件列表
+// @Description 分页获取当前用户上传的文件，按上传时间倒序，支持按类型和上传日期筛选
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Param type query string false "MIME类型，如image/png；不含斜杠时按大类筛选，如image"
+// @Param start_date query string false "上传日期起始(含)，格式2006-01-02"
+// @Param end_date query string false "上传日期截止(含)，格式2006-01-02"
+// @Success 200 {object} resp.FileListResponse "获取成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/files [get]
+func (h *FileHandler) GetMyFiles(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	request, ok := bindFileListRequest(c)
+	if !ok {
+		return
+	}
+	request.UploaderID = userID
+
+	resp, err := h.fileService.GetFiles(c.Request.Context(), request)
+	if err != nil {
+		utils.Error(c, 3002, err.Error())
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// GetAllFiles 获取全部文件列表
+// @Summary 获取全部文件列表
+// @Description 管理员分页获取所有用户上传的文件，支持按上传者、类型和上传日期筛选
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Param uploader_id query int false "上传者ID"
+// @Param type query string false "MIME类型，如image/png；不含斜杠时按大类筛选，如image"
+// @Param start_date query string false "上传日期起始(含)，格式2006-01-02"
+// @Param end_date query string false "上传日期截止(含)，格式2006-01-02"
+// @Success 200 {object} resp.FileListResponse "获取成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/admin/files [get]
+func (h *FileHandler) GetAllFiles(c *gin.Context) {
+	request, ok := bindFileListRequest(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.fileService.GetFiles(c.Request.Context(), request)
+	if err != nil {
+		utils.Error(c, 3002, err.Error())
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// GetFile 获取文件信息
+// @Summary 获取文件信息
+// @Description 获取文件的元数据，仅上传者或有管理文件权限的用户可查看
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文件ID"
+// @Success 200 {object} entity.File "获取成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/files/{id} [get]
+func (h *FileHandler) GetFile(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	fileID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文件ID")
+		return
+	}
+
+	file, err := h.fileService.GetFile(c.Request.Context(), uint(fileID), userID)
+	if err != nil {
+		utils.Error(c, 3003, err.Error())
+		return
+	}
+
+	utils.Success(c, file)
+}
+
+// RenameFile 修改文件名称
+// @Summary 修改文件名称
+// @Description 修改文件的显示名称(original_name)，不影响存储路径和访问URL；上传者或有管理文件权限的用户可操作
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文件ID"
+// @Param request body req.FileRenameRequest true "新的文件名称"
+// @Success 200 {object} entity.File "修改成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/files/{id} [put]
+func (h *FileHandler) RenameFile(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	fileID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文件ID")
+		return
+	}
+
+	var request req.FileRenameRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	file, err := h.fileService.RenameFile(c.Request.Context(), uint(fileID), userID, request.Name)
+	if err != nil {
+		utils.Error(c, 3005, err.Error())
+		return
+	}
+
+	utils.Success(c, file)
+}
+
+// DeleteFile 删除文件
+// @Summary 删除文件
+// @Description 删除文件记录和存储中的文件，上传者或有管理文件权限的用户可操作
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文件ID"
+// @Success 200 {object} utils.Response "删除成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/files/{id} [delete]
+func (h *FileHandler) DeleteFile(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	fileID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文件ID")
+		return
+	}
+
+	// 使用统一事务处理，存储删除失败时回滚数据库记录
+	err = utils.WithTransaction(c, func(ctx context.Context) error {
+		return h.fileService.DeleteFile(ctx, uint(fileID), userID)
+	})
+	if err != nil {
+		utils.Error(c, 3004, err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// bindFileListRequest 绑定文件列表查询参数
+func bindFileListRequest(c *gin.Context) (*req.FileListRequest, bool) {
+	request := &req.FileListRequest{
+		Page:     1,
+		PageSize: 10,
+	}
+	if err := c.ShouldBindQuery(request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return nil, false
+	}
+	return request, true
+}
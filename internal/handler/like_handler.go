@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"strconv"
+
+	"blog/internal/middleware"
+	"blog/internal/service"
+	"blog/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LikeHandler struct {
+	likeService *service.LikeService
+}
+
+func NewLikeHandler(likeService *service.LikeService) *LikeHandler {
+	return &LikeHandler{
+		likeService: likeService,
+	}
+}
+
+// LikeArticle 点赞文章
+// @Summary 点赞文章
+// @Description 点赞指定文章，重复点赞不会重复计数
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Success 200 {object} resp.ArticleLikeResponse "点赞成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/articles/{id}/like [post]
+func (h *LikeHandler) LikeArticle(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	resp, err := h.likeService.LikeArticle(c.Request.Context(), uint(id), userID)
+	if err != nil {
+		utils.Error(c, 2006, err.Error())
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// UnlikeArticle 取消点赞
+// @Summary 取消点赞
+// @Description 取消对指定文章的点赞
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Success 200 {object} resp.ArticleLikeResponse "取消成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/articles/{id}/like [delete]
+func (h *LikeHandler) UnlikeArticle(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	resp, err := h.likeService.UnlikeArticle(c.Request.Context(), uint(id), userID)
+	if err != nil {
+		utils.Error(c, 2007, err.Error())
+		return
+	}
+
+	utils.Success(c, resp)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oauthNonceCookie 绑定发起授权的浏览器的cookie，仅第三方登录接口可见
+const (
+	oauthNonceCookie     = "oauth_nonce"
+	oauthNonceCookiePath = "/api/v1/oauth"
+)
+
+type OAuthHandler struct {
+	oauthService *service.OAuthService
+}
+
+func NewOAuthHandler(oauthService *service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+	}
+}
+
+// GetProviders 获取第三方登录方式
+// @Summary 获取第三方登录方式
+// @Description 获取已启用的第三方登录提供方名称
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Success 200 {array} string "获取成功"
+// @Router /api/v1/oauth/providers [get]
+func (h *OAuthHandler) GetProviders(c *gin.Context) {
+	utils.Success(c, h.oauthService.GetProviders())
+}
+
+// Authorize 获取第三方授权地址
+// @Summary 获取第三方授权地址
+// @Description 生成第三方平台的授权页面地址和state，同时写入绑定当前浏览器的HttpOnly cookie。前端跳转到授权页面，用户同意后第三方平台携带code和state回调配置的redirect_url，登录时需在同一浏览器中携带该cookie
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Param provider path string true "提供方名称，如github、google"
+// @Success 200 {object} resp.OAuthAuthorizeResponse "获取成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Router /api/v1/oauth/{provider}/authorize [get]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	authorize, err := h.oauthService.Authorize(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		utils.Error(c, 1029, err.Error())
+		return
+	}
+
+	setOAuthNonceCookie(c, authorize.Nonce, int(service.OAuthStateTTL/time.Second))
+	utils.Success(c, authorize)
+}
+
+// Login 第三方登录
+// @Summary 第三方登录
+// @Description 使用第三方平台回调返回的code和state完成登录。已关联的第三方账号直接登录，否则按第三方平台已验证的邮箱关联本站账号，无法关联时自动注册；开启两步验证的用户返回challenge_token
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Param provider path string true "提供方名称，如github、google"
+// @Param request body req.OAuthLoginRequest true "授权码和state"
+// @Success 200 {object} resp.UserLoginResponse "登录成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Router /api/v1/oauth/{provider}/login [post]
+func (h *OAuthHandler) Login(c *gin.Context) {
+	var request req.OAuthLoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	request.IP = c.ClientIP()
+	request.UserAgent = c.Request.UserAgent()
+	request.Nonce, _ = c.Cookie(oauthNonceCookie)
+
+	// nonce与state一样只能使用一次
+	setOAuthNonceCookie(c, "", -1)
+
+	// 使用统一事务处理
+	resp, err := utils.WithTransactionResult(c, func(ctx context.Context) (*resp.UserLoginResponse, error) {
+		return h.oauthService.Login(ctx, c.Param("provider"), &request)
+	})
+	if err != nil {
+		utils.Error(c, 1030, err.Error())
+		return
+	}
+
+	// 隐藏密码
+	if resp.User != nil {
+		resp.User.Password = ""
+	}
+	utils.Success(c, resp)
+}
+
+// setOAuthNonceCookie 写入浏览器nonce cookie，maxAge小于0时删除
+func setOAuthNonceCookie(c *gin.Context, nonce string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthNonceCookie, nonce, maxAge, oauthNonceCookiePath, "", secure, true)
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"blog/internal/global"
+	"blog/internal/middleware"
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	oidcService *service.OIDCService
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// protocolError 按OAuth2规范返回错误，非协议错误统一返回server_error
+func (h *OIDCHandler) protocolError(c *gin.Context, err error) {
+	oidcErr, ok := service.IsOIDCError(err)
+	if !ok {
+		log.Printf("OIDC request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	status := http.StatusBadRequest
+	switch oidcErr.Code {
+	case "invalid_client", "invalid_token":
+		status = http.StatusUnauthorized
+	}
+	c.JSON(status, gin.H{"error": oidcErr.Code, "error_description": oidcErr.Description})
+}
+
+// Discovery OpenID Connect发现文档
+// @Summary OpenID Connect发现文档
+// @Description 返回本站作为OpenID Connect身份提供方的各端点地址和支持的能力
+// @Tags OpenID Connect
+// @Produce json
+// @Success 200 {object} resp.OIDCDiscovery "获取成功"
+// @Router /.well-known/openid-configuration [get]
+func (h *OIDCHandler) Discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, h.oidcService.Discovery())
+}
+
+// JWKS 签名公钥
+// @Summary 签名公钥
+// @Description 以JWK Set格式返回校验ID token签名的公钥
+// @Tags OpenID Connect
+// @Produce json
+// @Success 200 {object} utils.JWKSet "获取成功"
+// @Router /oauth2/jwks [get]
+func (h *OIDCHandler) JWKS(c *gin.Context) {
+	jwks, err := utils.GetJWKS()
+	if err != nil {
+		h.protocolError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, jwks)
+}
+
+// Authorize 授权端点
+// @Summary 授权端点
+// @Description 客户端将用户重定向到此地址发起授权码流程。参数校验通过后跳转到前端授权页面(oidc.login_url)，原样携带查询参数，由前端完成登录和授权确认；client_id或redirect_uri无效时直接返回错误，其它错误重定向回客户端
+// @Tags OpenID Connect
+// @Produce json
+// @Param response_type query string true "固定为code"
+// @Param client_id query string true "客户端ID"
+// @Param redirect_uri query string true "回调地址，须与配置完全一致"
+// @Param scope query string true "授权范围，须包含openid，可选profile、email"
+// @Param state query string false "客户端状态，原样返回"
+// @Param nonce query string false "写入ID token的nonce"
+// @Param code_challenge query string false "PKCE校验值，公开客户端必填"
+// @Param code_challenge_method query string false "固定为S256"
+// @Success 302 "跳转到前端授权页面"
+// @Failure 400 {object} map[string]string "参数错误"
+// @Router /oauth2/authorize [get]
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	var request req.OIDCAuthorizeRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+
+	// 客户端或回调地址无效时不能重定向，避免开放重定向
+	client, err := h.oidcService.FindClient(request.ClientID, request.RedirectURI)
+	if err != nil {
+		h.protocolError(c, err)
+		return
+	}
+
+	if err := h.oidcService.ValidateAuthorizeRequest(client, &request); err != nil {
+		oidcErr, _ := service.IsOIDCError(err)
+		params := url.Values{}
+		params.Set("error", oidcErr.Code)
+		params.Set("error_description", oidcErr.Description)
+		if request.State != "" {
+			params.Set("state", request.State)
+		}
+		c.Redirect(http.StatusFound, h.oidcService.AuthorizeRedirectURL(request.RedirectURI, params))
+		return
+	}
+
+	loginURL := global.Config.OIDC.LoginURL
+	sep := "?"
+	if strings.Contains(loginURL, "?") {
+		sep = "&"
+	}
+	c.Redirect(http.StatusFound, loginURL+sep+c.Request.URL.RawQuery)
+}
+
+// Consent 确认授权
+// @Summary 确认授权
+// @Description 前端授权页面在用户登录并同意后提交授权请求参数，签发授权码并返回携带code和state跳转回客户端的地址
+// @Tags OpenID Connect
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body req.OIDCAuthorizeRequest true "授权端点收到的查询参数"
+// @Success 200 {object} resp.OIDCAuthorizeResponse "授权成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "不支持使用访问令牌"
+// @Router /api/v1/oidc/authorize [post]
+func (h *OIDCHandler) Consent(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	var request req.OIDCAuthorizeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	authorize, err := h.oidcService.Authorize(c.Request.Context(), userID, middleware.GetCurrentSessionID(c), &request)
+	if err != nil {
+		if oidcErr, ok := service.IsOIDCError(err); ok {
+			utils.Error(c, 1031, oidcErr.Description)
+			return
+		}
+		utils.Error(c, 1031, "授权失败")
+		return
+	}
+
+	utils.Success(c, authorize)
+}
+
+// Token 令牌端点
+// @Summary 令牌端点
+// @Description 客户端使用授权码换取ID token和access token。机密客户端通过HTTP Basic认证或client_secret参数认证，公开客户端须提交code_verifier
+// @Tags OpenID Connect
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "固定为authorization_code"
+// @Param code formData string true "授权码"
+// @Param redirect_uri formData string true "与授权请求一致的回调地址"
+// @Param client_id formData string false "客户端ID"
+// @Param client_secret formData string false "客户端密钥"
+// @Param code_verifier formData string false "PKCE原始值"
+// @Success 200 {object} resp.OIDCTokenResponse "获取成功"
+// @Failure 400 {object} map[string]string "参数错误"
+// @Failure 401 {object} map[string]string "客户端认证失败"
+// @Router /oauth2/token [post]
+func (h *OIDCHandler) Token(c *gin.Context) {
+	var request req.OIDCTokenRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		// Basic认证的凭证按规范做了URL编码
+		if id, err := url.QueryUnescape(clientID); err == nil {
+			clientID = id
+		}
+		if secret, err := url.QueryUnescape(clientSecret); err == nil {
+			clientSecret = secret
+		}
+		request.ClientID = clientID
+		request.ClientSecret = clientSecret
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	token, err := h.oidcService.Token(c.Request.Context(), &request)
+	if err != nil {
+		h.protocolError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// UserInfo 用户信息端点
+// @Summary 用户信息端点
+// @Description 使用令牌端点签发的access token获取授权范围内的用户信息
+// @Tags OpenID Connect
+// @Produce json
+// @Param Authorization header string true "Bearer access_token"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 401 {object} map[string]string "令牌无效"
+// @Router /oauth2/userinfo [get]
+func (h *OIDCHandler) UserInfo(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" || token == c.GetHeader("Authorization") {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token", "error_description": "missing bearer token"})
+		return
+	}
+
+	claims, err := h.oidcService.UserInfo(c.Request.Context(), token)
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		h.protocolError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, claims)
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"blog/internal/middleware"
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/entity"
+	"blog/model/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RevisionHandler struct {
+	revisionService *service.RevisionService
+}
+
+func NewRevisionHandler(revisionService *service.RevisionService) *RevisionHandler {
+	return &RevisionHandler{
+		revisionService: revisionService,
+	}
+}
+
+// GetRevisions 获取文章修订历史
+// @Summary 获取文章修订历史
+// @Description 获取文章的所有修订版本(不含正文)，仅作者可查看
+// @Tags 文章修订
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Success 200 {array} entity.ArticleRevision "获取成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/articles/{id}/revisions [get]
+func (h *RevisionHandler) GetRevisions(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	revisions, err := h.revisionService.GetRevisions(c.Request.Context(), uint(articleID), userID)
+	if err != nil {
+		utils.Error(c, 2101, err.Error())
+		return
+	}
+
+	utils.Success(c, revisions)
+}
+
+// GetRevision 获取指定修订版本
+// @Summary 获取指定修订版本
+// @Description 获取文章指定版本的完整内容，仅作者可查看
+// @Tags 文章修订
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} entity.ArticleRevision "获取成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/articles/{id}/revisions/{version} [get]
+func (h *RevisionHandler) GetRevision(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		utils.BadRequest(c, "无效的版本号")
+		return
+	}
+
+	revision, err := h.revisionService.GetRevision(c.Request.Context(), uint(articleID), version, userID)
+	if err != nil {
+		utils.Error(c, 2102, err.Error())
+		return
+	}
+
+	utils.Success(c, revision)
+}
+
+// DiffRevisions 比较修订版本
+// @Summary 比较修订版本
+// @Description 比较文章两个版本正文的行级差异，仅作者可查看
+// @Tags 文章修订
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Param from query int true "起始版本号"
+// @Param to query int true "目标版本号"
+// @Success 200 {object} resp.RevisionDiffResponse "比较成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/articles/{id}/revisions/diff [get]
+func (h *RevisionHandler) DiffRevisions(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	var request req.RevisionDiffRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	diff, err := h.revisionService.DiffRevisions(c.Request.Context(), uint(articleID), userID, &request)
+	if err != nil {
+		utils.Error(c, 2103, err.Error())
+		return
+	}
+
+	utils.Success(c, diff)
+}
+
+// RestoreRevision 恢复修订版本
+// @Summary 恢复修订版本
+// @Description 将文章的标题、正文和摘要恢复到指定版本，恢复后产生一个新版本
+// @Tags 文章修订
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} entity.Article "恢复成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/articles/{id}/revisions/{version}/restore [post]
+func (h *RevisionHandler) RestoreRevision(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		utils.BadRequest(c, "无效的版本号")
+		return
+	}
+
+	// 使用统一事务处理
+	article, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.Article, error) {
+		return h.revisionService.RestoreRevision(ctx, uint(articleID), version, userID)
+	})
+	if err != nil {
+		utils.Error(c, 2104, err.Error())
+		return
+	}
+
+	utils.Success(c, article)
+}
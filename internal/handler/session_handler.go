@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"blog/internal/middleware"
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessionService *service.SessionService
+}
+
+func NewSessionHandler(sessionService *service.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// GetSessions 获取登录会话列表
+// @Summary 获取登录会话列表
+// @Description 获取当前用户所有有效的登录会话，包含登录IP、设备UA、登录时间和最近活跃时间
+// @Tags 会话管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} resp.SessionResponse "获取成功"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/sessions [get]
+func (h *SessionHandler) GetSessions(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	sessions, err := h.sessionService.GetSessions(c.Request.Context(), userID, middleware.GetCurrentSessionID(c))
+	if err != nil {
+		utils.Error(c, 1008, "获取会话列表失败")
+		return
+	}
+
+	utils.Success(c, sessions)
+}
+
+// RevokeSession 注销指定会话
+// @Summary 注销指定会话
+// @Description 注销当前用户的指定登录会话，该会话的token立即失效
+// @Tags 会话管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "会话ID"
+// @Success 200 {object} utils.Response "注销成功"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(c.Request.Context(), userID, c.Param("id")); err != nil {
+		utils.Error(c, 1009, err.Error())
+		return
+	}
+
+	utils.Success(c, "注销成功")
+}
+
+// RevokeOtherSessions 注销其他会话
+// @Summary 注销其他会话
+// @Description 注销当前用户除本次登录外的所有会话
+// @Tags 会话管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} resp.SessionRevokeResponse "注销成功"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/sessions/others [delete]
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	revoked, err := h.sessionService.RevokeOtherSessions(c.Request.Context(), userID, middleware.GetCurrentSessionID(c))
+	if err != nil {
+		utils.Error(c, 1010, "注销其他会话失败")
+		return
+	}
+
+	utils.Success(c, &resp.SessionRevokeResponse{Revoked: revoked})
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/entity"
+	"blog/model/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	tagService *service.TagService
+}
+
+func NewTagHandler(tagService *service.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+// GetTags 获取标签列表
+// @Summary 获取标签列表
+// @Description 获取所有标签及其已发布文章数
+// @Tags 标签管理
+// @Accept json
+// @Produce json
+// @Success 200 {array} resp.TagResponse "获取成功"
+// @Router /api/v1/tags [get]
+func (h *TagHandler) GetTags(c *gin.Context) {
+	tags, err := h.tagService.GetTags(c.Request.Context())
+	if err != nil {
+		utils.Error(c, 5001, err.Error())
+		return
+	}
+
+	utils.Success(c, tags)
+}
+
+// CreateTag 创建标签
+// @Summary 创建标签
+// @Description 创建新标签
+// @Tags 标签管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body req.TagCreateRequest true "标签信息"
+// @Success 200 {object} entity.Tag "创建成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/tags [post]
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var request req.TagCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	tag, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.Tag, error) {
+		return h.tagService.CreateTag(ctx, &request)
+	})
+	if err != nil {
+		utils.Error(c, 5002, err.Error())
+		return
+	}
+
+	utils.Success(c, tag)
+}
+
+// RenameTag 重命名标签
+// @Summary 重命名标签
+// @Description 重命名标签，关联文章的标签同步更新
+// @Tags 标签管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "标签ID"
+// @Param request body req.TagUpdateRequest true "标签信息"
+// @Success 200 {object} entity.Tag "更新成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/tags/{id} [put]
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的标签ID")
+		return
+	}
+
+	var request req.TagUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	tag, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.Tag, error) {
+		return h.tagService.RenameTag(ctx, uint(id), &request)
+	})
+	if err != nil {
+		utils.Error(c, 5003, err.Error())
+		return
+	}
+
+	utils.Success(c, tag)
+}
+
+// MergeTag 合并标签
+// @Summary 合并标签
+// @Description 将标签合并到目标标签，原标签下的文章改为关联目标标签
+// @Tags 标签管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "被合并的标签ID"
+// @Param request body req.TagMergeRequest true "目标标签"
+// @Success 200 {object} entity.Tag "合并成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Failure 403 {object} utils.Response "无权限"
+// @Router /api/v1/tags/{id}/merge [post]
+func (h *TagHandler) MergeTag(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的标签ID")
+		return
+	}
+
+	var request req.TagMergeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	tag, err := utils.WithTransactionResult(c, func(ctx context.Context) (*entity.Tag, error) {
+		return h.tagService.MergeTag(ctx, uint(id), &request)
+	})
+	if err != nil {
+		utils.Error(c, 5004, err.Error())
+		return
+	}
+
+	utils.Success(c, tag)
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+
+	"blog/internal/middleware"
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService *service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+// Setup 获取两步验证密钥
+// @Summary 获取两步验证密钥
+// @Description 生成新的TOTP密钥和otpauth链接，使用验证器App扫码后调用确认接口开启两步验证，密钥10分钟内有效
+// @Tags 账号安全
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} resp.TwoFactorSetupResponse "获取成功"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	setup, err := h.twoFactorService.Setup(c.Request.Context(), userID)
+	if err != nil {
+		utils.Error(c, 1020, err.Error())
+		return
+	}
+
+	utils.Success(c, setup)
+}
+
+// Confirm 开启两步验证
+// @Summary 开启两步验证
+// @Description 使用验证器App生成的验证码确认密钥并开启两步验证，返回的恢复码仅显示一次
+// @Tags 账号安全
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body req.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} resp.RecoveryCodesResponse "开启成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	var request req.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	codes, err := utils.WithTransactionResult(c, func(ctx context.Context) ([]string, error) {
+		return h.twoFactorService.Confirm(ctx, userID, &request)
+	})
+	if err != nil {
+		utils.Error(c, 1021, err.Error())
+		return
+	}
+
+	utils.Success(c, &resp.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 校验密码和验证码(或恢复码)后关闭两步验证，同时作废所有恢复码
+// @Tags 账号安全
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body req.TwoFactorDisableRequest true "密码和验证码"
+// @Success 200 {object} utils.Response "关闭成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	var request req.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	err := utils.WithTransaction(c, func(ctx context.Context) error {
+		return h.twoFactorService.Disable(ctx, userID, &request)
+	})
+	if err != nil {
+		utils.Error(c, 1022, err.Error())
+		return
+	}
+
+	utils.Success(c, "两步验证已关闭")
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 校验验证码后重新生成恢复码，旧恢复码全部作废，新恢复码仅显示一次
+// @Tags 账号安全
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body req.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} resp.RecoveryCodesResponse "生成成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	var request req.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	codes, err := utils.WithTransactionResult(c, func(ctx context.Context) ([]string, error) {
+		return h.twoFactorService.RegenerateRecoveryCodes(ctx, userID, &request)
+	})
+	if err != nil {
+		utils.Error(c, 1023, err.Error())
+		return
+	}
+
+	utils.Success(c, &resp.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Login 两步验证登录
+// @Summary 两步验证登录
+// @Description 使用登录接口返回的challenge_token和验证码(或恢复码)完成登录；每个challenge_token 5分钟内有效，最多验证5次
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body req.TwoFactorLoginRequest true "登录挑战和验证码"
+// @Success 200 {object} resp.UserLoginResponse "登录成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Router /api/v1/login/2fa [post]
+func (h *TwoFactorHandler) Login(c *gin.Context) {
+	var request req.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	request.IP = c.ClientIP()
+	request.UserAgent = c.Request.UserAgent()
+
+	// 使用统一事务处理
+	resp, err := utils.WithTransactionResult(c, func(ctx context.Context) (*resp.UserLoginResponse, error) {
+		return h.twoFactorService.Login(ctx, &request)
+	})
+	if err != nil {
+		utils.Error(c, 1024, err.Error())
+		return
+	}
+
+	// 隐藏密码
+	resp.User.Password = ""
+	utils.Success(c, resp)
+}
@@ -56,7 +56,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户登录获取JWT token
+// @Description 用户登录获取短期有效的access token和用于续期的refresh token；开启两步验证的用户返回two_factor_required和challenge_token，需继续调用两步验证登录接口；连续登录失败将按账号和IP退避等待，达到上限后临时锁定
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -71,6 +71,8 @@ func (h *UserHandler) Login(c *gin.Context) {
 		utils.BadRequest(c, "参数错误: "+err.Error())
 		return
 	}
+	request.IP = c.ClientIP()
+	request.UserAgent = c.Request.UserAgent()
 
 	// 使用统一事务处理
 	resp, err := utils.WithTransactionResult(c, func(ctx context.Context) (*resp.UserLoginResponse, error) {
@@ -82,10 +84,39 @@ func (h *UserHandler) Login(c *gin.Context) {
 	}
 
 	// 隐藏密码
-	resp.User.Password = ""
+	if resp.User != nil {
+		resp.User.Password = ""
+	}
 	utils.Success(c, resp)
 }
 
+// RefreshToken 刷新token
+// @Summary 刷新token
+// @Description 使用refresh token换取新的access token和refresh token，旧refresh token立即作废；重复使用已作废的refresh token将注销该登录会话的所有token
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body req.TokenRefreshRequest true "refresh token"
+// @Success 200 {object} resp.TokenResponse "刷新成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Router /api/v1/token/refresh [post]
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var request req.TokenRefreshRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	request.IP = c.ClientIP()
+
+	tokens, err := h.userService.RefreshToken(c.Request.Context(), &request)
+	if err != nil {
+		utils.Error(c, 1007, err.Error())
+		return
+	}
+
+	utils.Success(c, tokens)
+}
+
 // GetProfile 获取用户资料
 // @Summary 获取用户资料
 // @Description 获取当前登录用户的详细资料
@@ -149,6 +180,43 @@ func (h *UserHandler) Logout(c *gin.Context) {
 	utils.Success(c, "注销成功")
 }
 
+// ChangePassword 修改密码
+// @Summary 修改密码
+// @Description 校验当前密码后设置新密码，新密码需符合密码策略；修改成功后除当前会话外的所有token立即失效，个人访问令牌默认保留，revoke_access_tokens为true时一并删除
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body req.PasswordChangeRequest true "当前密码和新密码"
+// @Success 200 {object} utils.Response "修改成功"
+// @Failure 400 {object} utils.Response "参数错误"
+// @Failure 401 {object} utils.Response "未登录"
+// @Router /api/v1/profile/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		utils.Unauthorized(c, "未登录")
+		return
+	}
+
+	var request req.PasswordChangeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 使用统一事务处理
+	err := utils.WithTransaction(c, func(ctx context.Context) error {
+		return h.userService.ChangePassword(ctx, userID, middleware.GetCurrentSessionID(c), middleware.GetCurrentToken(c), &request)
+	})
+	if err != nil {
+		utils.Error(c, 1019, err.Error())
+		return
+	}
+
+	utils.Success(c, "密码修改成功")
+}
+
 // UpdateProfile 更新用户资料
 // @Summary 更新用户资料
 // @Description 更新当前登录用户的资料
@@ -1,11 +1,13 @@
 package init
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"blog/internal/global"
+	"blog/internal/service"
 	"blog/model/entity"
 
 	"gorm.io/driver/mysql"
@@ -78,6 +80,22 @@ func InitDB() error {
 	}
 
 	global.DB = db
+
+	// 迁移旧版逗号分隔的文章标签
+	if err := service.NewTagService().MigrateLegacyTags(context.Background()); err != nil {
+		return fmt.Errorf("failed to migrate legacy tags: %v", err)
+	}
+
+	// 为旧文章生成slug
+	if err := service.NewArticleService().MigrateArticleSlugs(context.Background()); err != nil {
+		return fmt.Errorf("failed to migrate article slugs: %v", err)
+	}
+
+	// 为配置中指定的用户授予管理员角色
+	if err := service.NewUserService().EnsureAdmins(context.Background(), global.Config.RBAC.Admins); err != nil {
+		return fmt.Errorf("failed to grant admin role: %v", err)
+	}
+
 	log.Println("Database initialized successfully")
 	return nil
 }
@@ -88,6 +106,15 @@ func autoMigrate(db *gorm.DB) error {
 		&entity.User{},
 		&entity.Article{},
 		&entity.File{},
+		&entity.Comment{},
+		&entity.Tag{},
+		&entity.Category{},
+		&entity.ArticleRevision{},
+		&entity.ArticleSlug{},
+		&entity.ArticleLike{},
+		&entity.RecoveryCode{},
+		&entity.AccessToken{},
+		&entity.SocialAccount{},
 		&entity.Test{},
 	)
 }
@@ -24,6 +24,11 @@ func InitHTTPServer() *gin.Engine {
 	// 创建路由
 	r := router.SetupRouter()
 
+	// 只有来自可信代理的请求才使用X-Forwarded-For中的客户端IP
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("Invalid server.trusted_proxies:", err)
+	}
+
 	log.Println("HTTP server initialized successfully")
 	return r
 }
@@ -36,6 +36,36 @@ func Initialize() error {
 		return err
 	}
 
+	// 6. 初始化全文检索
+	if err := InitSearch(); err != nil {
+		log.Fatal("Failed to initialize search:", err)
+		return err
+	}
+
+	// 7. 初始化文件存储
+	if err := InitStorage(); err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+		return err
+	}
+
+	// 8. 初始化邮件发送
+	if err := InitMailer(); err != nil {
+		log.Fatal("Failed to initialize mailer:", err)
+		return err
+	}
+
+	// 9. 初始化第三方登录
+	if err := InitOAuth(); err != nil {
+		log.Fatal("Failed to initialize OAuth:", err)
+		return err
+	}
+
+	// 10. 启动后台任务
+	if err := InitWorkers(); err != nil {
+		log.Fatal("Failed to initialize workers:", err)
+		return err
+	}
+
 	log.Println("All components initialized successfully")
 	return nil
 }
@@ -3,11 +3,26 @@ package init
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"blog/internal/global"
 	"blog/internal/utils"
 )
 
+// jwtKeyRefreshInterval 重新加载签名密钥的间隔，同时作为新密钥的启用延迟
+const jwtKeyRefreshInterval = 5 * time.Minute
+
+// minJWTSecretLen HMAC密钥的最小长度
+const minJWTSecretLen = 32
+
+// insecureJWTSecrets 曾作为默认值、示例或提交在仓库配置中的密钥，不允许使用
+var insecureJWTSecrets = []string{
+	"your-secret-key",
+	"default-jwt-secret-please-change-in-production",
+	"your-strong-jwt-secret-key-at-least-32-characters",
+	"qwertyuiopasdfghjklzxcvbnm,u6ytgjh",
+}
+
 // InitJWT 初始化JWT配置
 func InitJWT() error {
 	cfg := global.Config
@@ -15,12 +30,56 @@ func InitJWT() error {
 		return fmt.Errorf("config not initialized")
 	}
 
-	// 设置JWT密钥
+	// 设置HMAC密钥，用于邮件链接签名，未配置或使用公开的默认值时拒绝启动
+	if err := checkJWTSecret(cfg.JWT.Secret); err != nil {
+		return err
+	}
 	utils.SetJWTSecret(cfg.JWT.Secret)
+
+	// 旧密钥至少保留到用它签发的token全部过期
+	retention := time.Duration(cfg.JWT.KeyRetentionHour) * time.Hour
+	if ttl := time.Duration(cfg.JWT.AccessExpireMinute) * time.Minute; retention < ttl {
+		retention = ttl
+	}
+	if ttl := time.Duration(cfg.OIDC.IDTokenExpireMinute) * time.Minute; retention < ttl {
+		retention = ttl
+	}
+
+	// 加载JWT签名密钥，新密钥在各实例都重新加载后才启用
+	keySet, err := utils.NewKeySet(
+		cfg.JWT.KeyDir,
+		cfg.JWT.Algorithm,
+		time.Duration(cfg.JWT.RotateIntervalHour)*time.Hour,
+		jwtKeyRefreshInterval,
+		retention,
+	)
+	if err != nil {
+		return fmt.Errorf("load jwt signing keys: %w", err)
+	}
+	if cfg.JWT.KeyDir == "" {
+		log.Println("Warning: jwt.key_dir is empty, signing keys are kept in memory only")
+	}
+	utils.SetKeySet(keySet)
 	
 	// 设置JWT过期时间
-	utils.SetJWTExpireHour(cfg.JWT.ExpireHour)
+	utils.SetJWTExpireMinute(cfg.JWT.AccessExpireMinute)
 
 	log.Println("JWT initialized successfully")
 	return nil
 }
+
+// checkJWTSecret 检查HMAC密钥是否足够安全
+func checkJWTSecret(secret string) error {
+	if secret == "" {
+		return fmt.Errorf("jwt.secret is not set")
+	}
+	for _, insecure := range insecureJWTSecrets {
+		if secret == insecure {
+			return fmt.Errorf("jwt.secret is a well-known default value, please change it")
+		}
+	}
+	if len(secret) < minJWTSecretLen {
+		return fmt.Errorf("jwt.secret must be at least %d characters", minJWTSecretLen)
+	}
+	return nil
+}
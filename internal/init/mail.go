@@ -0,0 +1,33 @@
+package init
+
+import (
+	"fmt"
+	"log"
+
+	"blog/internal/global"
+	"blog/internal/mail"
+)
+
+// InitMailer 初始化邮件发送
+func InitMailer() error {
+	cfg := global.Config
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	switch cfg.Mail.Driver {
+	case "smtp":
+		global.Mailer = mail.NewSMTPMailer(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.From)
+	case "log", "":
+		mailer, err := mail.NewLogMailer(cfg.Mail.From, cfg.Mail.Dir)
+		if err != nil {
+			return err
+		}
+		global.Mailer = mailer
+	default:
+		return fmt.Errorf("unknown mail driver: %s", cfg.Mail.Driver)
+	}
+
+	log.Println("Mailer initialized successfully")
+	return nil
+}
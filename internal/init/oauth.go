@@ -0,0 +1,41 @@
+package init
+
+import (
+	"fmt"
+	"log"
+
+	"blog/internal/global"
+	"blog/internal/oauth"
+)
+
+// InitOAuth 初始化第三方登录提供方，未配置client_id的提供方不启用
+func InitOAuth() error {
+	cfg := global.Config
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	providers := make(map[string]oauth.Provider)
+	for name, p := range cfg.OAuth.Providers {
+		if p.ClientID == "" {
+			continue
+		}
+		provider, err := oauth.NewProvider(name, oauth.Config{
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+			AuthURL:      p.AuthURL,
+			TokenURL:     p.TokenURL,
+			UserInfoURL:  p.UserInfoURL,
+		})
+		if err != nil {
+			return err
+		}
+		providers[name] = provider
+	}
+	global.OAuthProviders = providers
+
+	log.Printf("OAuth initialized successfully (%d providers)", len(providers))
+	return nil
+}
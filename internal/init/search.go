@@ -0,0 +1,69 @@
+package init
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"blog/internal/global"
+	"blog/internal/search"
+	"blog/model/entity"
+
+	"gorm.io/gorm"
+)
+
+// InitSearch 初始化全文检索
+func InitSearch() error {
+	cfg := global.Config
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	switch cfg.Search.Engine {
+	case "memory":
+		searcher, err := buildMemorySearcher()
+		if err != nil {
+			return err
+		}
+		global.Searcher = searcher
+	case "mysql", "":
+		searcher, err := search.NewMySQLSearcher(global.DB)
+		if err != nil {
+			// MySQL不支持ngram全文索引时退回内存索引
+			log.Printf("Warning: MySQL fulltext search unavailable, falling back to memory index: %v", err)
+			memorySearcher, err := buildMemorySearcher()
+			if err != nil {
+				return err
+			}
+			global.Searcher = memorySearcher
+		} else {
+			global.Searcher = searcher
+		}
+	default:
+		return fmt.Errorf("unknown search engine: %s", cfg.Search.Engine)
+	}
+
+	log.Println("Search initialized successfully")
+	return nil
+}
+
+// buildMemorySearcher 加载全部文章构建内存索引
+func buildMemorySearcher() (*search.MemorySearcher, error) {
+	searcher := search.NewMemorySearcher()
+	ctx := context.Background()
+
+	var articles []entity.Article
+	err := global.DB.Select("id", "title", "content").FindInBatches(&articles, 200, func(tx *gorm.DB, batch int) error {
+		for _, a := range articles {
+			if err := searcher.Index(ctx, &search.Document{ID: a.ID, Title: a.Title, Content: a.Content}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to build search index: %v", err)
+	}
+
+	return searcher, nil
+}
@@ -0,0 +1,52 @@
+package init
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"blog/internal/global"
+	"blog/internal/storage"
+)
+
+// InitStorage 初始化文件存储
+func InitStorage() error {
+	cfg := global.Config
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	// 未配置时使用MinIO，与引入存储驱动之前的行为保持一致，本地存储需显式开启
+	switch cfg.Storage.Driver {
+	case "minio", "":
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		s, err := storage.NewMinioStorage(ctx,
+			cfg.Minio.Endpoint,
+			cfg.Minio.AccessKeyID,
+			cfg.Minio.SecretAccessKey,
+			cfg.Minio.UseSSL,
+			cfg.Minio.BucketName,
+			cfg.Minio.BaseURL,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to connect MinIO (set storage.driver to local to store files on disk): %w", err)
+		}
+		global.Storage = s
+	case "local":
+		s, err := storage.NewLocalStorage(cfg.Storage.Local.Dir, cfg.Storage.Local.BaseURL)
+		if err != nil {
+			return err
+		}
+		global.Storage = s
+	case "memory":
+		log.Println("Warning: storage.driver is memory, uploaded files are lost on restart")
+		global.Storage = storage.NewMemoryStorage()
+	default:
+		return fmt.Errorf("unknown storage driver: %s", cfg.Storage.Driver)
+	}
+
+	log.Println("Storage initialized successfully")
+	return nil
+}
@@ -0,0 +1,54 @@
+package init
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"blog/internal/global"
+	"blog/internal/service"
+	"blog/internal/utils"
+)
+
+// InitWorkers 启动后台任务
+func InitWorkers() error {
+	cfg := global.Config
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	// 计数回写
+	flushInterval := time.Duration(cfg.Counter.FlushInterval) * time.Second
+	if flushInterval <= 0 {
+		flushInterval = time.Minute
+	}
+	go runEvery("counter flush", flushInterval, service.FlushCounters)
+
+	// 定时发布
+	publishInterval := time.Duration(cfg.Scheduler.PublishInterval) * time.Second
+	if publishInterval <= 0 {
+		publishInterval = 30 * time.Second
+	}
+	go runEvery("article publish scheduler", publishInterval, service.PublishScheduledArticles)
+
+	// JWT签名密钥轮换
+	go runEvery("jwt key rotation", jwtKeyRefreshInterval, func(ctx context.Context) error {
+		return utils.RefreshSigningKeys()
+	})
+
+	log.Println("Workers initialized successfully")
+	return nil
+}
+
+// runEvery 按固定间隔执行后台任务
+func runEvery(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := fn(context.Background()); err != nil {
+			log.Printf("Worker %s failed: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogMailer 将邮件输出到日志，用于本地开发和测试
+// 指定目录时同时将邮件保存为.eml文件
+type LogMailer struct {
+	from string
+	dir  string
+}
+
+func NewLogMailer(from, dir string) (*LogMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &LogMailer{from: from, dir: dir}, nil
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	if !validAddress(msg.To) {
+		return errors.New("invalid recipient address")
+	}
+
+	log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+
+	if m.dir == "" {
+		return nil
+	}
+	name := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To))
+	return os.WriteFile(filepath.Join(m.dir, name), build(m.from, msg), 0o644)
+}
@@ -0,0 +1,166 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"unicode"
+
+	"blog/internal/utils"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	// Version 渲染规则版本，修改渲染或过滤规则时递增以使缓存失效
+	Version = 1
+
+	// 阅读速度：中文每分钟字数、英文每分钟单词数
+	hanPerMinute  = 300
+	wordPerMinute = 200
+)
+
+// Heading 目录项
+type Heading struct {
+	Level    int        `json:"level"`
+	ID       string     `json:"id"`
+	Text     string     `json:"text"`
+	Children []*Heading `json:"children,omitempty"`
+}
+
+// Result 渲染结果
+type Result struct {
+	HTML           string     `json:"html"`
+	TOC            []*Heading `json:"toc"`
+	ReadingMinutes int        `json:"reading_minutes"`
+}
+
+var (
+	engine = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		// 允许原始HTML，输出统一经过白名单过滤
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+
+	policy = newPolicy()
+)
+
+// newPolicy 创建HTML白名单，在UGC策略基础上允许标题锚点和代码语言标记
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[a-z0-9-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// Render 将Markdown渲染为过滤后的HTML，并生成目录和预计阅读时长
+func Render(source string) (*Result, error) {
+	src := []byte(source)
+	doc := engine.Parser().Parse(text.NewReader(src), parser.WithContext(parser.NewContext(parser.WithIDs(newIDs()))))
+
+	var buf bytes.Buffer
+	if err := engine.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		HTML:           policy.Sanitize(buf.String()),
+		TOC:            buildTOC(doc, src),
+		ReadingMinutes: readingMinutes(source),
+	}, nil
+}
+
+// buildTOC 根据文档中的标题生成嵌套目录
+func buildTOC(doc ast.Node, src []byte) []*Heading {
+	var toc []*Heading
+	var stack []*Heading
+
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		h, ok := node.(*ast.Heading)
+		if !ok {
+			continue
+		}
+		heading := &Heading{Level: h.Level, Text: string(h.Text(src))}
+		if id, ok := h.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				heading.ID = string(b)
+			}
+		}
+
+		// 弹出同级及更低级的标题，栈顶即为父标题
+		for len(stack) > 0 && stack[len(stack)-1].Level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			toc = append(toc, heading)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, heading)
+		}
+		stack = append(stack, heading)
+	}
+	return toc
+}
+
+// readingMinutes 估算阅读时长，中文按字数、其他语言按单词数计算，至少1分钟
+func readingMinutes(source string) int {
+	var han, words int
+	inWord := false
+	for _, r := range source {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+				inWord = true
+			}
+		default:
+			inWord = false
+		}
+	}
+
+	minutes := int(math.Ceil(float64(han)/hanPerMinute + float64(words)/wordPerMinute))
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
+
+// ids 标题锚点生成器，中文标题转换为拼音，重复时追加数字后缀
+type ids struct {
+	used map[string]bool
+}
+
+func newIDs() *ids {
+	return &ids{used: make(map[string]bool)}
+}
+
+func (s *ids) Generate(value []byte, kind ast.NodeKind) []byte {
+	base := utils.Slugify(string(value))
+	if base == "" {
+		base = "heading"
+	}
+	id := base
+	for i := 1; s.used[id]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	s.used[id] = true
+	return []byte(id)
+}
+
+func (s *ids) Put(value []byte) {
+	s.used[string(value)] = true
+}
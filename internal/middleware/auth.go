@@ -3,6 +3,7 @@ package middleware
 import (
 	"strings"
 
+	"blog/internal/rbac"
 	"blog/internal/service"
 	"blog/internal/utils"
 	"blog/model/entity"
@@ -10,7 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// Auth 认证中间件
+// Auth 认证中间件，支持登录获得的JWT和个人访问令牌
 func Auth(userService *service.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取Authorization header
@@ -32,19 +33,52 @@ func Auth(userService *service.UserService) gin.HandlerFunc {
 		token := parts[1]
 
 		// 验证token
-		user, err := userService.ValidateToken(token)
-		if err != nil {
+		if err := authenticate(c, userService, token); err != nil {
 			utils.Unauthorized(c, "无效的认证token")
 			c.Abort()
 			return
 		}
+		c.Set("token", token)
+
+		c.Next()
+	}
+}
+
+// OptionalAuth 可选认证中间件
+// 携带有效token时写入当前用户信息，未携带或token无效时按匿名访问处理
+func OptionalAuth(userService *service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			_ = authenticate(c, userService, parts[1])
+		}
+
+		c.Next()
+	}
+}
 
-		// 将用户信息存储到上下文
+// authenticate 验证JWT或个人访问令牌，验证通过后将当前用户写入上下文
+// 使用访问令牌时将令牌的授权范围写入请求上下文，供权限校验使用
+func authenticate(c *gin.Context, userService *service.UserService, token string) error {
+	if service.IsAccessToken(token) {
+		user, scopes, err := userService.ValidateAccessToken(c.Request.Context(), token)
+		if err != nil {
+			return err
+		}
+		c.Request = c.Request.WithContext(rbac.WithScopes(c.Request.Context(), scopes))
 		c.Set("user", user)
 		c.Set("user_id", user.ID)
+		return nil
+	}
 
-		c.Next()
+	user, claims, err := userService.ValidateToken(token)
+	if err != nil {
+		return err
 	}
+	c.Set("user", user)
+	c.Set("user_id", user.ID)
+	c.Set("session_id", claims.SessionID)
+	return nil
 }
 
 // GetCurrentUser 从上下文获取当前用户
@@ -64,3 +98,19 @@ func GetCurrentUserID(c *gin.Context) (uint, bool) {
 	}
 	return userID.(uint), true
 }
+
+// GetCurrentToken 从上下文获取当前请求使用的token
+func GetCurrentToken(c *gin.Context) string {
+	return c.GetString("token")
+}
+
+// GetCurrentSessionID 从上下文获取当前登录会话ID
+func GetCurrentSessionID(c *gin.Context) string {
+	return c.GetString("session_id")
+}
+
+// IsAccessTokenRequest 判断当前请求是否使用个人访问令牌认证
+func IsAccessTokenRequest(c *gin.Context) bool {
+	_, ok := rbac.ScopesFrom(c.Request.Context())
+	return ok
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"blog/internal/rbac"
+	"blog/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 权限校验中间件，要求当前用户的角色拥有全部指定权限，需在Auth之后使用
+// 使用个人访问令牌时，令牌的授权范围也必须包含全部指定权限
+func RequirePermission(perms ...rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := GetCurrentUser(c)
+		if !exists {
+			utils.Unauthorized(c, "未登录")
+			c.Abort()
+			return
+		}
+
+		for _, perm := range perms {
+			if !rbac.HasPermission(user.Role, perm) {
+				utils.Forbidden(c, "无权限执行此操作")
+				c.Abort()
+				return
+			}
+			if !rbac.ScopeAllows(c.Request.Context(), perm) {
+				utils.Forbidden(c, "访问令牌未授权此操作")
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireScope 访问令牌授权范围校验中间件，使用个人访问令牌时要求授权范围包含全部指定权限
+// 用于资源所有者即可操作、不要求角色权限的接口，登录会话不受影响
+func RequireScope(perms ...rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, perm := range perms {
+			if !rbac.ScopeAllows(c.Request.Context(), perm) {
+				utils.Forbidden(c, "访问令牌未授权此操作")
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// DenyAccessToken 拒绝个人访问令牌，用于账号安全相关的接口，只允许通过登录会话访问
+func DenyAccessToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsAccessTokenRequest(c) {
+			utils.Forbidden(c, "该操作不支持使用访问令牌")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,73 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// githubProvider GitHub登录，邮箱从/user/emails接口获取主邮箱及其验证状态
+type githubProvider struct {
+	*client
+}
+
+func newGitHubProvider(cfg Config) *githubProvider {
+	return &githubProvider{newClient(cfg, Config{
+		AuthURL:     "https://github.com/login/oauth/authorize",
+		TokenURL:    "https://github.com/login/oauth/access_token",
+		UserInfoURL: "https://api.github.com/user",
+		Scopes:      []string{"read:user", "user:email"},
+	})}
+}
+
+func (p *githubProvider) Name() string {
+	return "github"
+}
+
+func (p *githubProvider) AuthCodeURL(state string) string {
+	return p.authCodeURL(state)
+}
+
+func (p *githubProvider) Exchange(ctx context.Context, code string) (*UserInfo, error) {
+	accessToken, err := p.exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.getJSON(ctx, p.cfg.UserInfoURL, accessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errors.New("github user response missing id")
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getJSON(ctx, p.cfg.UserInfoURL+"/emails", accessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	info := &UserInfo{
+		ID:        strconv.FormatInt(user.ID, 10),
+		Username:  user.Login,
+		Name:      user.Name,
+		AvatarURL: user.AvatarURL,
+	}
+	for _, e := range emails {
+		if e.Primary {
+			info.Email = e.Email
+			info.EmailVerified = e.Verified
+			break
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,60 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// googleProvider Google登录，使用OpenID Connect的userinfo接口
+type googleProvider struct {
+	*client
+}
+
+func newGoogleProvider(cfg Config) *googleProvider {
+	return &googleProvider{newClient(cfg, Config{
+		AuthURL:     "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:    "https://oauth2.googleapis.com/token",
+		UserInfoURL: "https://openidconnect.googleapis.com/v1/userinfo",
+		Scopes:      []string{"openid", "email", "profile"},
+	})}
+}
+
+func (p *googleProvider) Name() string {
+	return "google"
+}
+
+func (p *googleProvider) AuthCodeURL(state string) string {
+	return p.authCodeURL(state)
+}
+
+func (p *googleProvider) Exchange(ctx context.Context, code string) (*UserInfo, error) {
+	accessToken, err := p.exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	var user struct {
+		Sub           string `json:"sub"`
+		Name          string `json:"name"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Picture       string `json:"picture"`
+	}
+	if err := p.getJSON(ctx, p.cfg.UserInfoURL, accessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.Sub == "" {
+		return nil, errors.New("google userinfo response missing sub")
+	}
+
+	username, _, _ := strings.Cut(user.Email, "@")
+	return &UserInfo{
+		ID:            user.Sub,
+		Username:      username,
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		AvatarURL:     user.Picture,
+	}, nil
+}
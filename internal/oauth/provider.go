@@ -0,0 +1,167 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// UserInfo 第三方账号信息
+type UserInfo struct {
+	ID            string // 第三方平台的用户唯一标识
+	Username      string
+	Name          string
+	Email         string
+	EmailVerified bool // 邮箱是否已由第三方平台验证，只有已验证的邮箱才会用于关联本地账号
+	AvatarURL     string
+}
+
+// Provider OAuth2授权码登录提供方
+type Provider interface {
+	// Name 提供方名称，如github、google
+	Name() string
+	// AuthCodeURL 生成跳转到第三方授权页面的地址
+	AuthCodeURL(state string) string
+	// Exchange 使用授权码换取access token并获取第三方账号信息
+	Exchange(ctx context.Context, code string) (*UserInfo, error)
+}
+
+// Config 提供方配置，地址为空时使用提供方的默认地址
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+}
+
+// NewProvider 根据名称创建提供方
+func NewProvider(name string, cfg Config) (Provider, error) {
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return nil, fmt.Errorf("oauth provider %s: client_id and client_secret are required", name)
+	}
+
+	switch name {
+	case "github":
+		return newGitHubProvider(cfg), nil
+	case "google":
+		return newGoogleProvider(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown oauth provider: %s", name)
+	}
+}
+
+// client 授权码模式的通用实现
+type client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+func newClient(cfg Config, defaults Config) *client {
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = defaults.AuthURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = defaults.TokenURL
+	}
+	if cfg.UserInfoURL == "" {
+		cfg.UserInfoURL = defaults.UserInfoURL
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = defaults.Scopes
+	}
+	return &client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// authCodeURL 生成授权页面地址
+func (c *client) authCodeURL(state string) string {
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", c.cfg.ClientID)
+	query.Set("redirect_uri", c.cfg.RedirectURL)
+	query.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	query.Set("state", state)
+
+	sep := "?"
+	if strings.Contains(c.cfg.AuthURL, "?") {
+		sep = "&"
+	}
+	return c.cfg.AuthURL + sep + query.Encode()
+}
+
+// exchange 使用授权码换取access token
+func (c *client) exchange(ctx context.Context, code string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.cfg.RedirectURL)
+	form.Set("client_id", c.cfg.ClientID)
+	form.Set("client_secret", c.cfg.ClientSecret)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := c.do(request, &token); err != nil {
+		return "", err
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("oauth token error: %s %s", token.Error, token.ErrorDescription)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("oauth token response missing access_token")
+	}
+	return token.AccessToken, nil
+}
+
+// getJSON 携带access token请求第三方接口
+func (c *client) getJSON(ctx context.Context, rawURL, accessToken string, v interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	request.Header.Set("Accept", "application/json")
+	return c.do(request, v)
+}
+
+// do 发送请求并解析JSON响应
+func (c *client) do(request *http.Request, v interface{}) error {
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	// token接口出错时也可能返回400及error字段，交由调用方处理
+	if response.StatusCode >= 300 && response.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("oauth request %s failed: %s", request.URL.Path, response.Status)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("oauth request %s: invalid response: %w", request.URL.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package rbac
+
+// 角色
+const (
+	RoleAdmin  = "admin"  // 管理员：拥有全部权限
+	RoleEditor = "editor" // 编辑：可管理所有文章、评论、文件及标签分类
+	RoleAuthor = "author" // 作者：可发布文章、上传文件
+	RoleReader = "reader" // 读者：只能评论和点赞
+)
+
+// DefaultRole 新注册用户的角色
+const DefaultRole = RoleAuthor
+
+// Permission 权限标识
+type Permission string
+
+const (
+	PermArticleWrite    Permission = "article:write"    // 发布和编辑自己的文章
+	PermArticleModerate Permission = "article:moderate" // 查看、编辑和删除任意文章
+	PermCommentWrite    Permission = "comment:write"    // 发表评论
+	PermCommentModerate Permission = "comment:moderate" // 删除任意评论
+	PermFileUpload      Permission = "file:upload"      // 上传文件
+	PermFileModerate    Permission = "file:moderate"    // 删除任意文件
+	PermTaxonomyManage  Permission = "taxonomy:manage"  // 管理标签和分类
+	PermUserManage      Permission = "user:manage"      // 管理用户角色和状态
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleAdmin: {
+		PermArticleWrite, PermArticleModerate,
+		PermCommentWrite, PermCommentModerate,
+		PermFileUpload, PermFileModerate,
+		PermTaxonomyManage, PermUserManage,
+	},
+	RoleEditor: {
+		PermArticleWrite, PermArticleModerate,
+		PermCommentWrite, PermCommentModerate,
+		PermFileUpload, PermFileModerate,
+		PermTaxonomyManage,
+	},
+	RoleAuthor: {
+		PermArticleWrite,
+		PermCommentWrite,
+		PermFileUpload,
+	},
+	RoleReader: {
+		PermCommentWrite,
+	},
+}
+
+// IsValidRole 判断角色是否存在
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Permissions 获取角色拥有的权限
+func Permissions(role string) []Permission {
+	return rolePermissions[role]
+}
+
+// HasPermission 判断角色是否拥有指定权限
+func HasPermission(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManage 判断用户能否操作资源：资源所有者，或拥有对应管理权限的角色
+func CanManage(userID uint, role string, ownerID uint, perm Permission) bool {
+	return userID == ownerID || HasPermission(role, perm)
+}
+
+// IsValidPermission 判断权限是否存在
+func IsValidPermission(perm Permission) bool {
+	for _, perms := range rolePermissions {
+		for _, p := range perms {
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package rbac
+
+import (
+	"context"
+	"strings"
+)
+
+type scopesKey struct{}
+
+// WithScopes 记录当前请求所用访问令牌的授权范围
+func WithScopes(ctx context.Context, scopes []Permission) context.Context {
+	return context.WithValue(ctx, scopesKey{}, scopes)
+}
+
+// ScopesFrom 获取当前请求的授权范围，使用登录会话(JWT)认证时返回false
+func ScopesFrom(ctx context.Context) ([]Permission, bool) {
+	scopes, ok := ctx.Value(scopesKey{}).([]Permission)
+	return scopes, ok
+}
+
+// ScopeAllows 判断当前请求的授权范围是否包含指定权限，登录会话不受授权范围限制
+func ScopeAllows(ctx context.Context, perm Permission) bool {
+	scopes, ok := ScopesFrom(ctx)
+	if !ok {
+		return true
+	}
+	for _, p := range scopes {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScopes 解析逗号分隔的授权范围
+func ParseScopes(s string) []Permission {
+	scopes := make([]Permission, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			scopes = append(scopes, Permission(item))
+		}
+	}
+	return scopes
+}
+
+// JoinScopes 将授权范围拼接为逗号分隔的字符串
+func JoinScopes(scopes []Permission) string {
+	items := make([]string, len(scopes))
+	for i, p := range scopes {
+		items[i] = string(p)
+	}
+	return strings.Join(items, ",")
+}
@@ -1,9 +1,12 @@
 package router
 
 import (
+	"blog/internal/global"
 	"blog/internal/handler"
 	"blog/internal/middleware"
+	"blog/internal/rbac"
 	"blog/internal/service"
+	"blog/internal/storage"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -16,39 +19,112 @@ import (
 func SetupRouter() *gin.Engine {
 	r := gin.Default()
 
+	// 默认不信任任何代理，ClientIP直接取连接地址，避免伪造X-Forwarded-For绕过按IP的限制
+	// 部署在反向代理之后时由InitHTTPServer按配置设置可信代理
+	_ = r.SetTrustedProxies(nil)
+
 	// 添加中间件
 	r.Use(middleware.CORS())
 	r.Use(middleware.Logger())
 
 	// 初始化服务
 	userService := service.NewUserService()
+	sessionService := service.NewSessionService()
+	accountService := service.NewAccountService(userService)
+	twoFactorService := service.NewTwoFactorService()
+	accessTokenService := service.NewAccessTokenService()
+	oauthService := service.NewOAuthService()
+	oidcService := service.NewOIDCService(userService)
 	articleService := service.NewArticleService()
 	fileService := service.NewFileService()
+	commentService := service.NewCommentService()
+	likeService := service.NewLikeService()
+	tagService := service.NewTagService()
+	categoryService := service.NewCategoryService()
+	revisionService := service.NewRevisionService(articleService)
+	feedService := service.NewFeedService()
 	testService := service.NewTestService()
 
 	// 初始化处理器
 	userHandler := handler.NewUserHandler(userService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	adminHandler := handler.NewAdminHandler(userService)
+	accountHandler := handler.NewAccountHandler(accountService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	accessTokenHandler := handler.NewAccessTokenHandler(accessTokenService)
+	oauthHandler := handler.NewOAuthHandler(oauthService)
+	oidcHandler := handler.NewOIDCHandler(oidcService)
 	articleHandler := handler.NewArticleHandler(articleService)
 	fileHandler := handler.NewFileHandler(fileService)
+	commentHandler := handler.NewCommentHandler(commentService)
+	likeHandler := handler.NewLikeHandler(likeService)
+	tagHandler := handler.NewTagHandler(tagService)
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+	revisionHandler := handler.NewRevisionHandler(revisionService)
+	feedHandler := handler.NewFeedHandler(feedService)
 	testHandler := handler.NewTestHandler(testService)
 	apifoxHandler := handler.NewApifoxHandler()
 
 	// Swagger文档路由
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// 本地存储的文件由本服务提供访问
+	if local, ok := global.Storage.(*storage.LocalStorage); ok {
+		static := r.Group("/static", func(c *gin.Context) {
+			// 禁止浏览器猜测内容类型，避免上传的文件被当作HTML执行
+			c.Header("X-Content-Type-Options", "nosniff")
+		})
+		static.Static("/", local.Dir())
+	}
+
 	// Apifox导入页面
 	r.GET("/apifox", apifoxHandler.GetApifoxQuickImport)
 
+	// 订阅源
+	r.GET("/feed.xml", feedHandler.RSS)
+	r.GET("/atom.xml", feedHandler.Atom)
+	r.GET("/feed.json", feedHandler.JSONFeed)
+
+	// OpenID Connect身份提供方
+	r.GET("/.well-known/openid-configuration", oidcHandler.Discovery)
+	r.GET("/oauth2/authorize", oidcHandler.Authorize)
+	r.POST("/oauth2/token", oidcHandler.Token)
+	r.GET("/oauth2/userinfo", oidcHandler.UserInfo)
+	r.POST("/oauth2/userinfo", oidcHandler.UserInfo)
+	r.GET("/oauth2/jwks", oidcHandler.JWKS)
+
 	// 公开路由
 	api := r.Group("/api/v1")
 	{
 		// 用户管理
 		api.POST("/register", userHandler.Register)
 		api.POST("/login", userHandler.Login)
+		api.POST("/login/2fa", twoFactorHandler.Login)
+		api.POST("/token/refresh", userHandler.RefreshToken)
+
+		// 第三方登录
+		api.GET("/oauth/providers", oauthHandler.GetProviders)
+		api.GET("/oauth/:provider/authorize", oauthHandler.Authorize)
+		api.POST("/oauth/:provider/login", oauthHandler.Login)
+
+		// 邮箱验证与找回密码
+		api.POST("/verify-email", accountHandler.VerifyEmail)
+		api.POST("/resend-verification", accountHandler.ResendVerification)
+		api.POST("/forgot-password", accountHandler.ForgotPassword)
+		api.POST("/reset-password", accountHandler.ResetPassword)
 
 		// 文章管理
-		api.GET("/articles", articleHandler.GetArticles)
-		api.GET("/articles/:id", articleHandler.GetArticle)
+		api.GET("/articles", middleware.OptionalAuth(userService), articleHandler.GetArticles)
+		api.GET("/search", articleHandler.SearchArticles)
+		api.GET("/articles/:id", middleware.OptionalAuth(userService), articleHandler.GetArticle)
+		api.GET("/articles/slug/:slug", middleware.OptionalAuth(userService), articleHandler.GetArticleBySlug)
+
+		// 评论管理
+		api.GET("/articles/:id/comments", commentHandler.GetComments)
+
+		// 标签与分类
+		api.GET("/tags", tagHandler.GetTags)
+		api.GET("/categories", categoryHandler.GetCategories)
 
 		// 测试管理
 		api.POST("/test", testHandler.CreateTest)
@@ -61,22 +137,88 @@ func SetupRouter() *gin.Engine {
 		api.GET("/apifox/import", apifoxHandler.GetApifoxImportInfo)
 	}
 
-	// 需要认证的路由
+	// 需要认证的路由，同时接受JWT和个人访问令牌
+	// 访问令牌只能调用其授权范围覆盖的接口，账号安全相关接口只允许登录会话访问
 	auth := api.Group("")
 	auth.Use(middleware.Auth(userService))
 	{
 		// 用户管理
 		auth.GET("/profile", userHandler.GetProfile)
-		auth.POST("/logout", userHandler.Logout)
-		auth.PUT("/profile", userHandler.UpdateProfile)
+
+		// 账号安全等操作仅允许登录会话访问，不接受访问令牌
+		session := auth.Group("", middleware.DenyAccessToken())
+		session.POST("/logout", userHandler.Logout)
+		session.PUT("/profile", userHandler.UpdateProfile)
+		session.PUT("/profile/password", userHandler.ChangePassword)
+
+		// 两步验证
+		session.POST("/2fa/setup", twoFactorHandler.Setup)
+		session.POST("/2fa/confirm", twoFactorHandler.Confirm)
+		session.POST("/2fa/disable", twoFactorHandler.Disable)
+		session.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+
+		// 会话管理
+		session.GET("/sessions", sessionHandler.GetSessions)
+		session.DELETE("/sessions/others", sessionHandler.RevokeOtherSessions)
+		session.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+
+		// 访问令牌管理
+		session.GET("/tokens", accessTokenHandler.GetAccessTokens)
+		session.POST("/tokens", accessTokenHandler.CreateAccessToken)
+		session.DELETE("/tokens/:id", accessTokenHandler.RevokeAccessToken)
+
+		// OpenID Connect授权确认
+		session.POST("/oidc/authorize", oidcHandler.Consent)
 
 		// 文章管理
-		auth.POST("/articles", articleHandler.CreateArticle)
-		auth.PUT("/articles/:id", articleHandler.UpdateArticle)
-		auth.DELETE("/articles/:id", articleHandler.DeleteArticle)
+		auth.POST("/articles", middleware.RequirePermission(rbac.PermArticleWrite), articleHandler.CreateArticle)
+		auth.PUT("/articles/:id", middleware.RequireScope(rbac.PermArticleWrite), articleHandler.UpdateArticle)
+		auth.DELETE("/articles/:id", middleware.RequireScope(rbac.PermArticleWrite), articleHandler.DeleteArticle)
+		session.POST("/articles/:id/like", likeHandler.LikeArticle)
+		session.DELETE("/articles/:id/like", likeHandler.UnlikeArticle)
+
+		// 文章修订
+		revisions := auth.Group("", middleware.RequireScope(rbac.PermArticleWrite))
+		revisions.GET("/articles/:id/revisions", revisionHandler.GetRevisions)
+		revisions.GET("/articles/:id/revisions/diff", revisionHandler.DiffRevisions)
+		revisions.GET("/articles/:id/revisions/:version", revisionHandler.GetRevision)
+		revisions.POST("/articles/:id/revisions/:version/restore", revisionHandler.RestoreRevision)
+
+		// 评论管理
+		auth.POST("/articles/:id/comments", middleware.RequirePermission(rbac.PermCommentWrite), commentHandler.CreateComment)
+		auth.PUT("/articles/:id/comments/:comment_id", middleware.RequireScope(rbac.PermCommentWrite), commentHandler.UpdateComment)
+		auth.DELETE("/articles/:id/comments/:comment_id", middleware.RequireScope(rbac.PermCommentWrite), commentHandler.DeleteComment)
+
+		// 标签与分类
+		taxonomy := auth.Group("", middleware.RequirePermission(rbac.PermTaxonomyManage))
+		taxonomy.POST("/tags", tagHandler.CreateTag)
+		taxonomy.PUT("/tags/:id", tagHandler.RenameTag)
+		taxonomy.POST("/tags/:id/merge", tagHandler.MergeTag)
+		taxonomy.POST("/categories", categoryHandler.CreateCategory)
+		taxonomy.PUT("/categories/:id", categoryHandler.UpdateCategory)
+		taxonomy.POST("/categories/:id/merge", categoryHandler.MergeCategory)
 
 		// 文件管理
-		auth.POST("/upload", fileHandler.Upload)
+		auth.POST("/upload", middleware.RequirePermission(rbac.PermFileUpload), fileHandler.Upload)
+		files := auth.Group("/files", middleware.RequireScope(rbac.PermFileUpload))
+		files.GET("", fileHandler.GetMyFiles)
+		files.GET("/:id", fileHandler.GetFile)
+		files.PUT("/:id", fileHandler.RenameFile)
+		files.DELETE("/:id", fileHandler.DeleteFile)
+
+		// 全部文件，需要管理文件权限
+		auth.GET("/admin/files", middleware.RequirePermission(rbac.PermFileModerate), fileHandler.GetAllFiles)
+	}
+
+	// 后台管理路由
+	admin := auth.Group("/admin")
+	admin.Use(middleware.RequirePermission(rbac.PermUserManage))
+	{
+		admin.PUT("/users/:id/role", adminHandler.UpdateUserRole)
+		admin.POST("/users/:id/disable", adminHandler.DisableUser)
+		admin.POST("/users/:id/suspend", adminHandler.SuspendUser)
+		admin.POST("/users/:id/enable", adminHandler.EnableUser)
+		admin.POST("/users/:id/unlock", adminHandler.UnlockLogin)
 	}
 
 	return r
@@ -0,0 +1,140 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+)
+
+// titleWeight 标题中的词频权重
+const titleWeight = 3
+
+// MemorySearcher 进程内倒排索引检索实现
+// 启动时由全量文章构建，之后随文章增删改同步更新
+type MemorySearcher struct {
+	mu       sync.RWMutex
+	postings map[string]map[uint]int // 词 -> 文档ID -> 加权词频
+	docTerms map[uint]map[string]int // 文档ID -> 词 -> 加权词频，用于更新和删除
+	docLens  map[uint]int
+	totalLen int
+}
+
+// NewMemorySearcher 创建内存检索器
+func NewMemorySearcher() *MemorySearcher {
+	return &MemorySearcher{
+		postings: make(map[string]map[uint]int),
+		docTerms: make(map[uint]map[string]int),
+		docLens:  make(map[uint]int),
+	}
+}
+
+// Index 新增或更新文档索引
+func (s *MemorySearcher) Index(ctx context.Context, doc *Document) error {
+	terms := make(map[string]int)
+	for _, t := range Tokenize(doc.Title) {
+		terms[t] += titleWeight
+	}
+	for _, t := range Tokenize(doc.Content) {
+		terms[t]++
+	}
+
+	length := 0
+	for _, tf := range terms {
+		length += tf
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(doc.ID)
+	for t, tf := range terms {
+		if s.postings[t] == nil {
+			s.postings[t] = make(map[uint]int)
+		}
+		s.postings[t][doc.ID] = tf
+	}
+	s.docTerms[doc.ID] = terms
+	s.docLens[doc.ID] = length
+	s.totalLen += length
+	return nil
+}
+
+// Delete 删除文档索引
+func (s *MemorySearcher) Delete(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(id)
+	return nil
+}
+
+// remove 移除文档索引，调用方需持有写锁
+func (s *MemorySearcher) remove(id uint) {
+	terms, ok := s.docTerms[id]
+	if !ok {
+		return
+	}
+
+	for t := range terms {
+		delete(s.postings[t], id)
+		if len(s.postings[t]) == 0 {
+			delete(s.postings, t)
+		}
+	}
+	s.totalLen -= s.docLens[id]
+	delete(s.docTerms, id)
+	delete(s.docLens, id)
+}
+
+// Search 使用BM25算法计算相关度，最多返回MaxHits条
+// 内存索引不包含文章状态、作者等信息，调用方在截断后的结果中再做筛选，
+// 关键词过于宽泛时筛选后的结果和总数可能少于实际匹配的文章数
+func (s *MemorySearcher) Search(ctx context.Context, query string) ([]Hit, error) {
+	const k1, b = 1.2, 0.75
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n := len(s.docTerms)
+	if n == 0 {
+		return nil, nil
+	}
+	avgLen := float64(s.totalLen) / float64(n)
+
+	scores := make(map[uint]float64)
+	seen := make(map[string]bool)
+	for _, t := range Tokenize(query) {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+
+		docs := s.postings[t]
+		if len(docs) == 0 {
+			continue
+		}
+
+		idf := math.Log(1 + (float64(n)-float64(len(docs))+0.5)/(float64(len(docs))+0.5))
+		for id, tf := range docs {
+			norm := k1 * (1 - b + b*float64(s.docLens[id])/avgLen)
+			scores[id] += idf * float64(tf) * (k1 + 1) / (float64(tf) + norm)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID > hits[j].ID
+	})
+
+	if len(hits) > MaxHits {
+		hits = hits[:MaxHits]
+	}
+	return hits, nil
+}
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mysqlFulltextIndex 文章全文索引名称
+const mysqlFulltextIndex = "ft_articles_title_content"
+
+// MySQLSearcher 基于MySQL FULLTEXT索引(ngram分词)的检索实现
+// 索引由MySQL自动维护，Index/Delete无需处理
+type MySQLSearcher struct {
+	db *gorm.DB
+}
+
+// NewMySQLSearcher 创建MySQL检索器，索引不存在时自动创建
+func NewMySQLSearcher(db *gorm.DB) (*MySQLSearcher, error) {
+	var count int64
+	err := db.Raw(
+		"SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?",
+		"articles", mysqlFulltextIndex,
+	).Scan(&count).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		sql := fmt.Sprintf("ALTER TABLE articles ADD FULLTEXT INDEX %s (title, content) WITH PARSER ngram", mysqlFulltextIndex)
+		if err := db.Exec(sql).Error; err != nil {
+			return nil, fmt.Errorf("failed to create fulltext index: %v", err)
+		}
+	}
+
+	return &MySQLSearcher{db: db}, nil
+}
+
+// Index MySQL自动维护索引
+func (s *MySQLSearcher) Index(ctx context.Context, doc *Document) error {
+	return nil
+}
+
+// Delete MySQL自动维护索引
+func (s *MySQLSearcher) Delete(ctx context.Context, id uint) error {
+	return nil
+}
+
+// Match 使用自然语言模式检索，标题命中额外加权
+func (s *MySQLSearcher) Match(query string) (where clause.Expr, score clause.Expr) {
+	where = clause.Expr{SQL: "MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE)", Vars: []interface{}{query}}
+	score = clause.Expr{
+		SQL:  "MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE) + IF(title LIKE ?, 1, 0)",
+		Vars: []interface{}{query, "%" + query + "%"},
+	}
+	return where, score
+}
+
+// Search 按相关度返回命中结果，不区分文章状态
+func (s *MySQLSearcher) Search(ctx context.Context, query string) ([]Hit, error) {
+	where, score := s.Match(query)
+
+	var hits []Hit
+	err := s.db.WithContext(ctx).Table("articles").
+		Select("id, ? AS score", score).
+		Where("deleted_at IS NULL").
+		Where(where).
+		Order("score DESC").
+		Limit(MaxHits).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
@@ -0,0 +1,41 @@
+package search
+
+import (
+	"context"
+
+	"gorm.io/gorm/clause"
+)
+
+// MaxHits 单次检索返回的最大命中数
+// 仅对Search生效，实现了SQLMatcher的检索器在数据库查询中直接检索，不受此限制
+const MaxHits = 1000
+
+// Document 待索引的文章
+type Document struct {
+	ID      uint
+	Title   string
+	Content string
+}
+
+// Hit 检索命中结果
+type Hit struct {
+	ID    uint
+	Score float64
+}
+
+// Searcher 全文检索接口
+type Searcher interface {
+	// Index 新增或更新文档索引
+	Index(ctx context.Context, doc *Document) error
+	// Delete 删除文档索引
+	Delete(ctx context.Context, id uint) error
+	// Search 按相关度从高到低返回命中结果，最多返回 MaxHits 条
+	Search(ctx context.Context, query string) ([]Hit, error)
+}
+
+// SQLMatcher 可以在articles表查询中直接执行的检索实现
+// 检索条件与状态、作者等筛选条件在同一条SQL中执行，总数和分页不受MaxHits截断影响
+type SQLMatcher interface {
+	// Match 返回检索条件和相关度表达式
+	Match(query string) (where clause.Expr, score clause.Expr)
+}
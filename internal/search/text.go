@@ -0,0 +1,156 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Tokenize 分词：连续的中文按二元组切分(与MySQL ngram解析器一致)，其余按字母数字连续串切分并转小写
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var han []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	flushHan := func() {
+		switch {
+		case len(han) == 1:
+			tokens = append(tokens, string(han))
+		case len(han) > 1:
+			for i := 0; i+1 < len(han); i++ {
+				tokens = append(tokens, string(han[i:i+2]))
+			}
+		}
+		han = han[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+
+	return tokens
+}
+
+// Highlight 对文本中出现的关键词加上<em>标签，返回HTML转义后的结果
+func Highlight(text, query string) string {
+	runes := []rune(text)
+	marks := matchRanges(runes, query)
+	return renderHighlight(runes, marks, 0, len(runes))
+}
+
+// Snippet 截取包含首个关键词的摘要片段并高亮，maxRunes为片段最大字符数
+func Snippet(text, query string, maxRunes int) string {
+	runes := []rune(text)
+	marks := matchRanges(runes, query)
+
+	start := 0
+	if len(marks) > 0 {
+		// 让首个命中位置处于片段前部
+		start = marks[0][0] - maxRunes/4
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + maxRunes
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	snippet := renderHighlight(runes, marks, start, end)
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
+
+// matchRanges 查找关键词在文本中的所有出现位置，返回按起始位置排序且互不重叠的区间
+func matchRanges(runes []rune, query string) [][2]int {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	covered := make([]bool, len(runes))
+	for _, field := range strings.Fields(query) {
+		kw := []rune(strings.ToLower(field))
+		if len(kw) == 0 {
+			continue
+		}
+		for i := 0; i+len(kw) <= len(lower); i++ {
+			if runesEqual(lower[i:i+len(kw)], kw) {
+				for j := i; j < i+len(kw); j++ {
+					covered[j] = true
+				}
+			}
+		}
+	}
+
+	var ranges [][2]int
+	for i := 0; i < len(covered); i++ {
+		if !covered[i] {
+			continue
+		}
+		j := i
+		for j < len(covered) && covered[j] {
+			j++
+		}
+		ranges = append(ranges, [2]int{i, j})
+		i = j
+	}
+	return ranges
+}
+
+// renderHighlight 输出[start, end)范围内的文本，命中区间加<em>标签
+func renderHighlight(runes []rune, marks [][2]int, start, end int) string {
+	var b strings.Builder
+	pos := start
+	for _, m := range marks {
+		if m[1] <= start || m[0] >= end {
+			continue
+		}
+		from, to := m[0], m[1]
+		if from < start {
+			from = start
+		}
+		if to > end {
+			to = end
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:from])))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(string(runes[from:to])))
+		b.WriteString("</em>")
+		pos = to
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	return b.String()
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"blog/internal/global"
+	"blog/internal/rbac"
+	"blog/internal/utils"
+	"blog/model/entity"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// AccessTokenPrefix 个人访问令牌前缀，用于与JWT区分
+	AccessTokenPrefix = "blog_pat_"
+	// accessTokenDisplayLen 列表中展示的令牌长度
+	accessTokenDisplayLen = len(AccessTokenPrefix) + 6
+	// maxAccessTokens 每个用户最多可创建的访问令牌数量
+	maxAccessTokens = 50
+	// accessTokenTouchInterval 最近使用时间的更新间隔，避免每次请求都写数据库
+	accessTokenTouchInterval = time.Minute
+)
+
+// IsAccessToken 判断token是否为个人访问令牌
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// hashAccessToken 访问令牌仅以哈希形式保存
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type AccessTokenService struct{}
+
+func NewAccessTokenService() *AccessTokenService {
+	return &AccessTokenService{}
+}
+
+// getDB 获取数据库连接，支持事务
+func (s *AccessTokenService) getDB(ctx context.Context) *gorm.DB {
+	return global.GetDB(ctx)
+}
+
+// Create 创建访问令牌，授权范围不能超出用户当前角色的权限，完整令牌仅返回一次
+func (s *AccessTokenService) Create(ctx context.Context, userID uint, req *req.AccessTokenCreateRequest) (*resp.AccessTokenCreateResponse, error) {
+	db := s.getDB(ctx)
+
+	var user entity.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	scopes := make([]rbac.Permission, 0, len(req.Scopes))
+	seen := make(map[rbac.Permission]bool)
+	for _, item := range req.Scopes {
+		perm := rbac.Permission(strings.TrimSpace(item))
+		if !rbac.IsValidPermission(perm) {
+			return nil, errors.New("授权范围不存在: " + item)
+		}
+		if !rbac.HasPermission(user.Role, perm) {
+			return nil, errors.New("授权范围超出当前角色的权限: " + item)
+		}
+		if !seen[perm] {
+			seen[perm] = true
+			scopes = append(scopes, perm)
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("过期时间必须晚于当前时间")
+	}
+
+	var count int64
+	if err := db.Model(&entity.AccessToken{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= maxAccessTokens {
+		return nil, errors.New("访问令牌数量已达上限，请先删除不再使用的令牌")
+	}
+
+	random, err := utils.RandomString(20)
+	if err != nil {
+		return nil, err
+	}
+	token := AccessTokenPrefix + random
+
+	accessToken := &entity.AccessToken{
+		UserID:    userID,
+		Name:      req.Name,
+		TokenHash: hashAccessToken(token),
+		Prefix:    token[:accessTokenDisplayLen],
+		Scopes:    rbac.JoinScopes(scopes),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := db.Create(accessToken).Error; err != nil {
+		return nil, err
+	}
+
+	return &resp.AccessTokenCreateResponse{
+		AccessTokenResponse: *resp.ToAccessTokenResponse(accessToken),
+		Token:               token,
+	}, nil
+}
+
+// GetAccessTokens 获取用户的访问令牌列表
+func (s *AccessTokenService) GetAccessTokens(ctx context.Context, userID uint) ([]*resp.AccessTokenResponse, error) {
+	var tokens []entity.AccessToken
+	if err := s.getDB(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]*resp.AccessTokenResponse, 0, len(tokens))
+	for i := range tokens {
+		list = append(list, resp.ToAccessTokenResponse(&tokens[i]))
+	}
+	return list, nil
+}
+
+// revokeAccessTokens 删除用户的所有访问令牌，用于重置或修改密码后切断已泄露账号上的API访问
+func revokeAccessTokens(db *gorm.DB, userID uint) error {
+	return db.Where("user_id = ?", userID).Delete(&entity.AccessToken{}).Error
+}
+
+// Revoke 删除用户的访问令牌，令牌立即失效
+func (s *AccessTokenService) Revoke(ctx context.Context, userID, tokenID uint) error {
+	result := s.getDB(ctx).Where("id = ? AND user_id = ?", tokenID, userID).Delete(&entity.AccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("访问令牌不存在")
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"blog/internal/global"
+	"blog/model/entity"
+	"blog/model/req"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type AccountService struct {
+	userService *UserService
+}
+
+func NewAccountService(userService *UserService) *AccountService {
+	return &AccountService{
+		userService: userService,
+	}
+}
+
+// getDB 获取数据库连接，支持事务
+func (s *AccountService) getDB(ctx context.Context) *gorm.DB {
+	return global.GetDB(ctx)
+}
+
+// VerifyEmail 使用验证链接中的令牌完成邮箱验证
+func (s *AccountService) VerifyEmail(ctx context.Context, req *req.VerifyEmailRequest) error {
+	db := s.getDB(ctx)
+
+	userID, email, err := consumeEmailToken(ctx, purposeVerifyEmail, req.Token)
+	if err != nil {
+		return err
+	}
+
+	var user entity.User
+	if err := db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+
+	// 签发后修改过邮箱的，旧链接不能验证新邮箱
+	if user.Email != email {
+		return errors.New("邮箱已变更，请重新发送验证邮件")
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return db.Model(&user).Update("email_verified_at", time.Now()).Error
+}
+
+// ResendVerification 重新发送邮箱验证邮件
+// 邮箱未注册或已验证时同样返回成功，避免泄露邮箱是否注册
+func (s *AccountService) ResendVerification(ctx context.Context, req *req.EmailRequest) error {
+	user, err := s.findUserByEmail(ctx, req.Email)
+	if err != nil || user == nil || user.EmailVerifiedAt != nil {
+		return err
+	}
+
+	allowed, err := allowMail(ctx, purposeVerifyEmail, user.ID)
+	if err != nil || !allowed {
+		return err
+	}
+	return sendVerificationEmail(ctx, user)
+}
+
+// ForgotPassword 发送密码重置邮件
+// 邮箱未注册或账号被禁用时同样返回成功，避免泄露邮箱是否注册
+func (s *AccountService) ForgotPassword(ctx context.Context, req *req.EmailRequest) error {
+	user, err := s.findUserByEmail(ctx, req.Email)
+	if err != nil || user == nil || user.Status == 0 {
+		return err
+	}
+
+	allowed, err := allowMail(ctx, purposeResetPassword, user.ID)
+	if err != nil || !allowed {
+		return err
+	}
+	return sendPasswordResetEmail(ctx, user)
+}
+
+// ResetPassword 使用重置链接中的令牌设置新密码，并注销该用户的所有token
+func (s *AccountService) ResetPassword(ctx context.Context, req *req.ResetPasswordRequest) error {
+	db := s.getDB(ctx)
+
+	// 先校验密码强度，避免令牌因密码不合规被白白消耗
+	if err := checkPasswordPolicy(req.Password); err != nil {
+		return err
+	}
+
+	userID, email, err := consumeEmailToken(ctx, purposeResetPassword, req.Token)
+	if err != nil {
+		return err
+	}
+
+	var user entity.User
+	if err := db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+	if user.Email != email {
+		return errors.New("邮箱已变更，请重新申请重置密码")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{"password": string(hashedPassword)}
+	// 能收到重置邮件说明邮箱属于该用户
+	if user.EmailVerifiedAt == nil {
+		updates["email_verified_at"] = time.Now()
+	}
+	if err := db.Model(&user).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	// 重置密码通常意味着账号可能已泄露，删除所有访问令牌
+	if err := revokeAccessTokens(db, user.ID); err != nil {
+		return err
+	}
+
+	// 注销所有已登录的设备
+	return s.userService.LogoutAllDevices(ctx, user.ID)
+}
+
+// findUserByEmail 根据邮箱查找用户，不存在时返回nil
+func (s *AccountService) findUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	db := s.getDB(ctx)
+
+	var user entity.User
+	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
@@ -3,14 +3,20 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"blog/internal/global"
+	"blog/internal/rbac"
+	"blog/internal/search"
+	"blog/internal/utils"
 	"blog/model/entity"
 	"blog/model/req"
 	"blog/model/resp"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ArticleService struct{}
@@ -24,40 +30,92 @@ func (s *ArticleService) getDB(ctx context.Context) *gorm.DB {
 	return global.GetDB(ctx)
 }
 
+// preload 预加载文章的作者、分类和标签
+func (s *ArticleService) preload(db *gorm.DB) *gorm.DB {
+	return db.Preload("Author").Preload("Category").Preload("TagList")
+}
+
+// resolveCategory 校验分类是否存在，0表示不设置分类
+func (s *ArticleService) resolveCategory(ctx context.Context, categoryID uint) (*uint, error) {
+	if categoryID == 0 {
+		return nil, nil
+	}
+
+	var count int64
+	if err := s.getDB(ctx).Model(&entity.Category{}).Where("id = ?", categoryID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errors.New("分类不存在")
+	}
+	return &categoryID, nil
+}
+
 // CreateArticle 创建文章
 func (s *ArticleService) CreateArticle(ctx context.Context, userID uint, req *req.ArticleCreateRequest) (*entity.Article, error) {
 	db := s.getDB(ctx)
 
+	categoryID, err := s.resolveCategory(ctx, req.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
 	article := &entity.Article{
 		Title:      req.Title,
 		Content:    req.Content,
 		Summary:    req.Summary,
 		CoverImage: req.CoverImage,
 		AuthorID:   userID,
+		CategoryID: categoryID,
 		Status:     req.Status,
-		Tags:       req.Tags,
 	}
 
-	// 如果是发布状态，设置发布时间
-	if req.Status == 1 {
+	// 如果是发布状态，设置发布时间；定时发布使用计划发布时间
+	switch req.Status {
+	case 1:
 		now := time.Now()
 		article.PublishedAt = &now
+	case 2:
+		if err := checkPublishAt(req.PublishAt); err != nil {
+			return nil, err
+		}
+		article.PublishedAt = req.PublishAt
 	}
 
 	if err := db.Create(article).Error; err != nil {
 		return nil, err
 	}
 
-	// 预加载作者信息
-	if err := db.Preload("Author").First(article, article.ID).Error; err != nil {
+	// 设置文章标签
+	if err := setArticleTags(db, article, req.Tags); err != nil {
+		return nil, err
+	}
+
+	// 设置slug
+	if err := assignSlug(db, article, req.Slug); err != nil {
+		return nil, err
+	}
+
+	// 记录初始版本
+	if err := saveRevision(db, article, userID); err != nil {
+		return nil, err
+	}
+
+	// 预加载关联信息
+	if err := s.preload(db).First(article, article.ID).Error; err != nil {
+		return nil, err
+	}
+
+	// 同步全文索引
+	if err := indexArticle(ctx, article); err != nil {
 		return nil, err
 	}
 
 	return article, nil
 }
 
-// GetArticles 获取文章列表
-func (s *ArticleService) GetArticles(ctx context.Context, req *req.ArticleListRequest) (*resp.ArticleListResponse, error) {
+// GetArticles 获取文章列表，viewerID为当前访问用户ID(匿名为0)
+func (s *ArticleService) GetArticles(ctx context.Context, viewerID uint, req *req.ArticleListRequest) (*resp.ArticleListResponse, error) {
 	db := s.getDB(ctx)
 
 	var articles []entity.Article
@@ -65,36 +123,63 @@ func (s *ArticleService) GetArticles(ctx context.Context, req *req.ArticleListRe
 
 	query := db.Model(&entity.Article{})
 
-	// 添加查询条件
-	if req.Status != 0 {
-		query = query.Where("status = ?", req.Status)
+	// 添加查询条件，草稿和定时发布的文章仅作者本人可见
+	status := req.Status
+	if viewerID == 0 || req.AuthorID != viewerID {
+		status = 1
+	}
+	if status != 0 {
+		query = query.Where("status = ?", status)
 	}
 	if req.AuthorID != 0 {
 		query = query.Where("author_id = ?", req.AuthorID)
 	}
-	if req.Keyword != "" {
-		query = query.Where("title LIKE ? OR content LIKE ?", "%"+req.Keyword+"%", "%"+req.Keyword+"%")
-	}
 
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, err
+	if req.Tag != "" {
+		query = query.Where("id IN (?)", db.Table("article_tags").
+			Select("article_tags.article_id").
+			Joins("JOIN tags ON tags.id = article_tags.tag_id").
+			Where("tags.name = ?", req.Tag))
+	}
+	if req.Category != "" {
+		query = query.Where("category_id IN (?)", db.Model(&entity.Category{}).Select("id").Where("name = ?", req.Category))
 	}
 
-	// 分页查询
 	offset := (req.Page - 1) * req.PageSize
-	if err := query.Preload("Author").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(req.PageSize).
-		Find(&articles).Error; err != nil {
-		return nil, err
+
+	// 关键词检索结果按相关度排序
+	if req.Keyword != "" {
+		hits, count, err := searchArticles(ctx, query, req.Keyword, offset, req.PageSize)
+		if err != nil {
+			return nil, err
+		}
+		total = count
+		articles = []entity.Article{}
+		if err := s.findByHits(db, hits, &articles); err != nil {
+			return nil, err
+		}
+	} else {
+		// 获取总数
+		if err := query.Count(&total).Error; err != nil {
+			return nil, err
+		}
+
+		// 分页查询
+		if err := s.preload(query).
+			Order("created_at DESC").
+			Offset(offset).
+			Limit(req.PageSize).
+			Find(&articles).Error; err != nil {
+			return nil, err
+		}
 	}
 
 	// 处理响应数据
+	list := make([]*entity.Article, 0, len(articles))
 	for i := range articles {
-		resp.ToArticleResponse(&articles[i])
+		list = append(list, resp.ToArticleResponse(&articles[i]))
 	}
+	applyPendingCounts(ctx, list...)
 
 	return &resp.ArticleListResponse{
 		Articles: articles,
@@ -104,24 +189,65 @@ func (s *ArticleService) GetArticles(ctx context.Context, req *req.ArticleListRe
 	}, nil
 }
 
-// GetArticleByID 根据ID获取文章
-func (s *ArticleService) GetArticleByID(ctx context.Context, id uint) (*entity.Article, error) {
+// GetArticleByID 根据ID获取文章，viewerID为当前访问用户ID(匿名为0)
+func (s *ArticleService) GetArticleByID(ctx context.Context, id, viewerID uint) (*entity.Article, error) {
 	db := s.getDB(ctx)
 
 	var article entity.Article
-	if err := db.Preload("Author").First(&article, id).Error; err != nil {
+	if err := s.preload(db).First(&article, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("文章不存在")
 		}
 		return nil, err
 	}
 
-	// 增加浏览量
-	db.Model(&article).UpdateColumn("view_count", gorm.Expr("view_count + ?", 1))
+	// 草稿和定时发布的文章仅作者本人和文章管理员可见
+	if article.Status != 1 {
+		allowed, err := authorize(ctx, db, viewerID, article.AuthorID, rbac.PermArticleModerate)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("文章不存在")
+		}
+	}
 
+	applyPendingCounts(ctx, &article)
 	return resp.ToArticleResponse(&article), nil
 }
 
+// GetArticleBySlug 根据slug获取文章，slug可以是文章的历史slug
+func (s *ArticleService) GetArticleBySlug(ctx context.Context, slug string, viewerID uint) (*entity.Article, error) {
+	articleID, err := slugOwner(s.getDB(ctx), slug)
+	if err != nil {
+		return nil, err
+	}
+	if articleID == 0 {
+		return nil, errors.New("文章不存在")
+	}
+	return s.GetArticleByID(ctx, articleID, viewerID)
+}
+
+// RecordView 记录文章浏览
+// 同一访客在时间窗口内重复浏览只计一次，浏览量先缓冲在Redis中，由后台任务批量回写
+func (s *ArticleService) RecordView(ctx context.Context, articleID uint, visitor string) error {
+	window := time.Duration(global.Config.Counter.ViewWindow) * time.Minute
+	if window <= 0 {
+		window = 30 * time.Minute
+	}
+
+	viewKey := fmt.Sprintf("article_view:%d:%s", articleID, visitor)
+	first, err := global.Redis.SetNX(ctx, viewKey, 1, window).Result()
+	if err != nil {
+		return err
+	}
+	if !first {
+		return nil
+	}
+
+	return viewCounter.incr(ctx, articleID, 1)
+}
+
 // UpdateArticle 更新文章
 func (s *ArticleService) UpdateArticle(ctx context.Context, id, userID uint, req *req.ArticleUpdateRequest) (*entity.Article, error) {
 	db := s.getDB(ctx)
@@ -135,34 +261,87 @@ func (s *ArticleService) UpdateArticle(ctx context.Context, id, userID uint, req
 	}
 
 	// 检查权限
-	if article.AuthorID != userID {
+	allowed, err := authorize(ctx, db, userID, article.AuthorID, rbac.PermArticleModerate)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
 		return nil, errors.New("无权限修改此文章")
 	}
 
+	categoryID, err := s.resolveCategory(ctx, req.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 早于修订功能创建的文章，先记录修改前的内容作为基线版本
+	exists, err := hasRevisions(db, article.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := saveRevision(db, &article, article.AuthorID); err != nil {
+			return nil, err
+		}
+	}
+
 	// 更新字段
 	updates := make(map[string]interface{})
 	updates["title"] = req.Title
 	updates["content"] = req.Content
 	updates["summary"] = req.Summary
 	updates["cover_image"] = req.CoverImage
-	updates["tags"] = req.Tags
+	updates["category_id"] = categoryID
 
 	// 处理状态变更
 	if req.Status != article.Status {
 		updates["status"] = req.Status
-		if req.Status == 1 && article.PublishedAt == nil {
-			// 首次发布
+		if req.Status == 1 && (article.PublishedAt == nil || article.Status == 2) {
+			// 首次发布或提前发布定时文章
 			now := time.Now()
 			updates["published_at"] = &now
 		}
+		if req.Status == 0 && article.Status == 2 {
+			// 取消定时发布
+			updates["published_at"] = nil
+		}
+	}
+	if req.Status == 2 {
+		// 设置或调整计划发布时间
+		if err := checkPublishAt(req.PublishAt); err != nil {
+			return nil, err
+		}
+		updates["published_at"] = req.PublishAt
 	}
 
 	if err := db.Model(&article).Updates(updates).Error; err != nil {
 		return nil, err
 	}
 
+	// 更新文章标签
+	if err := setArticleTags(db, &article, req.Tags); err != nil {
+		return nil, err
+	}
+
+	// 作者指定新slug时更新，旧slug保留用于跳转
+	if req.Slug != "" || article.Slug == "" {
+		if err := assignSlug(db, &article, req.Slug); err != nil {
+			return nil, err
+		}
+	}
+
 	// 重新查询更新后的文章
-	if err := db.Preload("Author").First(&article, id).Error; err != nil {
+	if err := s.preload(db).First(&article, id).Error; err != nil {
+		return nil, err
+	}
+
+	// 记录本次修改后的版本
+	if err := saveRevision(db, &article, userID); err != nil {
+		return nil, err
+	}
+
+	// 同步全文索引
+	if err := indexArticle(ctx, &article); err != nil {
 		return nil, err
 	}
 
@@ -182,9 +361,200 @@ func (s *ArticleService) DeleteArticle(ctx context.Context, id, userID uint) err
 	}
 
 	// 检查权限
-	if article.AuthorID != userID {
+	allowed, err := authorize(ctx, db, userID, article.AuthorID, rbac.PermArticleModerate)
+	if err != nil {
+		return err
+	}
+	if !allowed {
 		return errors.New("无权限删除此文章")
 	}
 
-	return db.Delete(&article).Error
+	if err := db.Delete(&article).Error; err != nil {
+		return err
+	}
+
+	// 同步全文索引
+	return global.Searcher.Delete(ctx, article.ID)
+}
+
+// SearchArticles 全文检索已发布文章，按相关度排序并返回高亮片段
+func (s *ArticleService) SearchArticles(ctx context.Context, req *req.ArticleSearchRequest) (*resp.ArticleSearchResponse, error) {
+	db := s.getDB(ctx)
+
+	result := &resp.ArticleSearchResponse{
+		Results:  []*resp.ArticleSearchResult{},
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+
+	query := db.Model(&entity.Article{}).Where("status = ?", 1)
+	offset := (req.Page - 1) * req.PageSize
+	hits, total, err := searchArticles(ctx, query, req.Keyword, offset, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	result.Total = total
+
+	scores := make(map[uint]float64, len(hits))
+	for _, hit := range hits {
+		scores[hit.ID] = hit.Score
+	}
+
+	var articles []*entity.Article
+	if err := s.findByHits(db, hits, &articles); err != nil {
+		return nil, err
+	}
+	applyPendingCounts(ctx, articles...)
+
+	for _, article := range articles {
+		result.Results = append(result.Results, &resp.ArticleSearchResult{
+			Article:        resp.ToArticleResponse(article),
+			Score:          scores[article.ID],
+			TitleHighlight: search.Highlight(article.Title, req.Keyword),
+			Snippet:        search.Snippet(article.Content, req.Keyword, 160),
+		})
+		// 检索结果以片段代替全文
+		article.Content = ""
+	}
+
+	return result, nil
+}
+
+// MigrateArticleSlugs 为尚未设置slug的文章生成slug，可重复执行
+// 只有查询失败时返回错误，单篇文章失败时记录日志并跳过
+func (s *ArticleService) MigrateArticleSlugs(ctx context.Context) error {
+	db := s.getDB(ctx)
+
+	var articles []*entity.Article
+	if err := db.Select("id", "title", "slug").Where("slug = '' OR slug IS NULL").Find(&articles).Error; err != nil {
+		return err
+	}
+
+	migrated := 0
+	for _, article := range articles {
+		// 单篇文章生成失败不影响启动，下次启动时重试
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return assignSlug(tx, article, "")
+		})
+		if err != nil {
+			log.Printf("Warning: failed to generate slug of article %d: %v", article.ID, err)
+			continue
+		}
+		migrated++
+	}
+
+	if migrated > 0 {
+		log.Printf("Generated slugs for %d articles", migrated)
+	}
+	return nil
+}
+
+// PublishScheduledArticles 发布已到计划时间的定时文章
+// 多实例部署时通过Redis锁保证同一时间只有一个实例执行
+func PublishScheduledArticles(ctx context.Context) error {
+	unlock, ok, err := utils.TryLock(ctx, "lock:article_publish_scheduler", time.Minute)
+	if err != nil || !ok {
+		return err
+	}
+	defer unlock()
+
+	result := global.DB.WithContext(ctx).Model(&entity.Article{}).
+		Where("status = ? AND published_at <= ?", 2, time.Now()).
+		Update("status", 1)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Published %d scheduled articles", result.RowsAffected)
+	}
+	return nil
+}
+
+// checkPublishAt 校验定时发布时间
+func checkPublishAt(publishAt *time.Time) error {
+	if publishAt == nil {
+		return errors.New("定时发布需要设置发布时间")
+	}
+	if !publishAt.After(time.Now()) {
+		return errors.New("定时发布时间必须晚于当前时间")
+	}
+	return nil
+}
+
+// indexArticle 同步文章全文索引
+func indexArticle(ctx context.Context, article *entity.Article) error {
+	return global.Searcher.Index(ctx, &search.Document{
+		ID:      article.ID,
+		Title:   article.Title,
+		Content: article.Content,
+	})
+}
+
+// searchArticles 在query的筛选条件下检索文章，返回当前页的命中结果和总数
+// 检索器支持SQL检索时检索与筛选在同一条SQL中执行；否则在检索器返回的至多MaxHits条结果中筛选
+func searchArticles(ctx context.Context, query *gorm.DB, keyword string, offset, limit int) ([]search.Hit, int64, error) {
+	var hits []search.Hit
+	var total int64
+
+	if matcher, ok := global.Searcher.(search.SQLMatcher); ok {
+		where, score := matcher.Match(keyword)
+		query = query.Where(where)
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+		err := query.Select("id, ? AS score", score).
+			Order("score DESC, id DESC").
+			Offset(offset).
+			Limit(limit).
+			Scan(&hits).Error
+		return hits, total, err
+	}
+
+	ranked, err := global.Searcher.Search(ctx, keyword)
+	if err != nil || len(ranked) == 0 {
+		return nil, 0, err
+	}
+
+	ids := make([]uint, 0, len(ranked))
+	scores := make(map[uint]float64, len(ranked))
+	for _, hit := range ranked {
+		ids = append(ids, hit.ID)
+		scores[hit.ID] = hit.Score
+	}
+
+	query = query.Where("id IN ?", ids)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var pageIDs []uint
+	if err := query.Clauses(orderByIDs(ids)).
+		Offset(offset).
+		Limit(limit).
+		Pluck("id", &pageIDs).Error; err != nil {
+		return nil, 0, err
+	}
+	for _, id := range pageIDs {
+		hits = append(hits, search.Hit{ID: id, Score: scores[id]})
+	}
+	return hits, total, nil
+}
+
+// findByHits 按检索结果的顺序查询文章
+func (s *ArticleService) findByHits(db *gorm.DB, hits []search.Hit, dest interface{}) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return s.preload(db).Where("id IN ?", ids).Clauses(orderByIDs(ids)).Find(dest).Error
+}
+
+// orderByIDs 按给定的ID顺序排序
+func orderByIDs(ids []uint) clause.Expression {
+	return clause.OrderBy{
+		Expression: clause.Expr{SQL: "FIELD(id,?)", Vars: []interface{}{ids}, WithoutParentheses: true},
+	}
 }
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"blog/internal/global"
+	"blog/model/entity"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"gorm.io/gorm"
+)
+
+type CategoryService struct{}
+
+func NewCategoryService() *CategoryService {
+	return &CategoryService{}
+}
+
+// getDB 获取数据库连接，支持事务
+func (s *CategoryService) getDB(ctx context.Context) *gorm.DB {
+	return global.GetDB(ctx)
+}
+
+// GetCategories 获取所有分类及其已发布文章数
+func (s *CategoryService) GetCategories(ctx context.Context) ([]*resp.CategoryResponse, error) {
+	db := s.getDB(ctx)
+
+	var categories []*resp.CategoryResponse
+	err := db.Table("categories").
+		Select("categories.id, categories.name, categories.description, COUNT(articles.id) AS article_count").
+		Joins("LEFT JOIN articles ON articles.category_id = categories.id AND articles.status = 1 AND articles.deleted_at IS NULL").
+		Group("categories.id, categories.name, categories.description").
+		Order("categories.id ASC").
+		Scan(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// CreateCategory 创建分类
+func (s *CategoryService) CreateCategory(ctx context.Context, req *req.CategoryCreateRequest) (*entity.Category, error) {
+	db := s.getDB(ctx)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("分类名称不能为空")
+	}
+
+	var count int64
+	if err := db.Model(&entity.Category{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("分类已存在")
+	}
+
+	category := &entity.Category{
+		Name:        name,
+		Description: req.Description,
+	}
+	if err := db.Create(category).Error; err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategory 重命名分类或修改描述
+func (s *CategoryService) UpdateCategory(ctx context.Context, id uint, req *req.CategoryUpdateRequest) (*entity.Category, error) {
+	db := s.getDB(ctx)
+
+	category, err := s.getCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("分类名称不能为空")
+	}
+
+	var count int64
+	if err := db.Model(&entity.Category{}).Where("name = ? AND id <> ?", name, id).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("分类已存在，请使用合并功能")
+	}
+
+	updates := map[string]interface{}{
+		"name":        name,
+		"description": req.Description,
+	}
+	if err := db.Model(category).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	return s.getCategory(ctx, id)
+}
+
+// MergeCategory 将分类合并到目标分类，原分类下的文章移动到目标分类，原分类删除
+func (s *CategoryService) MergeCategory(ctx context.Context, id uint, req *req.CategoryMergeRequest) (*entity.Category, error) {
+	db := s.getDB(ctx)
+
+	if id == req.TargetID {
+		return nil, errors.New("不能合并到自身")
+	}
+
+	source, err := s.getCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.getCategory(ctx, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Unscoped().Model(&entity.Article{}).
+		Where("category_id = ?", source.ID).
+		UpdateColumn("category_id", target.ID).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Delete(source).Error; err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// getCategory 根据ID获取分类
+func (s *CategoryService) getCategory(ctx context.Context, id uint) (*entity.Category, error) {
+	db := s.getDB(ctx)
+
+	var category entity.Category
+	if err := db.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("分类不存在")
+		}
+		return nil, err
+	}
+	return &category, nil
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"blog/internal/global"
+	"blog/internal/rbac"
+	"blog/model/entity"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"gorm.io/gorm"
+)
+
+type CommentService struct{}
+
+func NewCommentService() *CommentService {
+	return &CommentService{}
+}
+
+// getDB 获取数据库连接，支持事务
+func (s *CommentService) getDB(ctx context.Context) *gorm.DB {
+	return global.GetDB(ctx)
+}
+
+// CreateComment 发表评论
+func (s *CommentService) CreateComment(ctx context.Context, articleID, userID uint, req *req.CommentCreateRequest) (*entity.Comment, error) {
+	db := s.getDB(ctx)
+
+	var article entity.Article
+	if err := db.First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("文章不存在")
+		}
+		return nil, err
+	}
+	if article.Status != 1 {
+		return nil, errors.New("文章未发布，无法评论")
+	}
+
+	comment := &entity.Comment{
+		ArticleID: articleID,
+		UserID:    userID,
+		Content:   req.Content,
+	}
+
+	// 回复评论时，记录父评论与顶级评论
+	if req.ParentID != 0 {
+		var parent entity.Comment
+		if err := db.Where("article_id = ?", articleID).First(&parent, req.ParentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("回复的评论不存在")
+			}
+			return nil, err
+		}
+		comment.ParentID = parent.ID
+		comment.RootID = parent.RootID
+		if comment.RootID == 0 {
+			comment.RootID = parent.ID
+		}
+	}
+
+	if err := db.Create(comment).Error; err != nil {
+		return nil, err
+	}
+
+	// 更新文章评论数
+	if err := db.Model(&article).UpdateColumn("comment_count", gorm.Expr("comment_count + ?", 1)).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Preload("User").First(comment, comment.ID).Error; err != nil {
+		return nil, err
+	}
+
+	return resp.ToCommentResponse(comment), nil
+}
+
+// GetComments 分页获取文章评论，按顶级评论分页并嵌套返回回复
+func (s *CommentService) GetComments(ctx context.Context, articleID uint, req *req.CommentListRequest) (*resp.CommentListResponse, error) {
+	db := s.getDB(ctx)
+
+	var roots []*entity.Comment
+	var total int64
+
+	query := db.Model(&entity.Comment{}).Where("article_id = ? AND parent_id = 0", articleID)
+
+	// 获取顶级评论总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.Preload("User").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&roots).Error; err != nil {
+		return nil, err
+	}
+
+	if len(roots) > 0 {
+		rootIDs := make([]uint, 0, len(roots))
+		for _, root := range roots {
+			rootIDs = append(rootIDs, root.ID)
+		}
+
+		// 一次性加载本页所有回复
+		var replies []*entity.Comment
+		if err := db.Preload("User").
+			Where("root_id IN ?", rootIDs).
+			Order("created_at ASC").
+			Find(&replies).Error; err != nil {
+			return nil, err
+		}
+
+		buildCommentTree(roots, replies)
+	}
+
+	for _, root := range roots {
+		resp.ToCommentResponse(root)
+	}
+
+	return &resp.CommentListResponse{
+		Comments: roots,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, nil
+}
+
+// UpdateComment 编辑评论，仅评论作者可编辑
+func (s *CommentService) UpdateComment(ctx context.Context, articleID, id, userID uint, req *req.CommentUpdateRequest) (*entity.Comment, error) {
+	db := s.getDB(ctx)
+
+	comment, err := s.getComment(ctx, articleID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查权限
+	if comment.UserID != userID {
+		return nil, errors.New("无权限修改此评论")
+	}
+
+	if err := db.Model(comment).Update("content", req.Content).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Preload("User").First(comment, id).Error; err != nil {
+		return nil, err
+	}
+
+	return resp.ToCommentResponse(comment), nil
+}
+
+// DeleteComment 删除评论及其所有回复，评论作者、文章作者或评论管理员可删除
+func (s *CommentService) DeleteComment(ctx context.Context, articleID, id, userID uint) error {
+	db := s.getDB(ctx)
+
+	comment, err := s.getComment(ctx, articleID, id)
+	if err != nil {
+		return err
+	}
+
+	var article entity.Article
+	if err := db.First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("文章不存在")
+		}
+		return err
+	}
+
+	// 检查权限
+	if article.AuthorID != userID {
+		allowed, err := authorize(ctx, db, userID, comment.UserID, rbac.PermCommentModerate)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return errors.New("无权限删除此评论")
+		}
+	}
+
+	// 找出该评论下的所有回复
+	rootID := comment.RootID
+	if rootID == 0 {
+		rootID = comment.ID
+	}
+	var thread []*entity.Comment
+	if err := db.Where("root_id = ?", rootID).Find(&thread).Error; err != nil {
+		return err
+	}
+	ids := append([]uint{comment.ID}, collectDescendantIDs(comment.ID, thread)...)
+
+	if err := db.Where("id IN ?", ids).Delete(&entity.Comment{}).Error; err != nil {
+		return err
+	}
+
+	// 更新文章评论数
+	return db.Model(&article).
+		UpdateColumn("comment_count", gorm.Expr("GREATEST(comment_count - ?, 0)", len(ids))).Error
+}
+
+// getComment 获取指定文章下的评论
+func (s *CommentService) getComment(ctx context.Context, articleID, id uint) (*entity.Comment, error) {
+	db := s.getDB(ctx)
+
+	var comment entity.Comment
+	if err := db.Where("article_id = ?", articleID).First(&comment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("评论不存在")
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// buildCommentTree 将回复挂载到对应的父评论下
+func buildCommentTree(roots, replies []*entity.Comment) {
+	nodes := make(map[uint]*entity.Comment, len(roots)+len(replies))
+	for _, c := range roots {
+		nodes[c.ID] = c
+	}
+	for _, c := range replies {
+		nodes[c.ID] = c
+	}
+
+	for _, c := range replies {
+		// 父评论已被删除的回复不再展示
+		if parent, ok := nodes[c.ParentID]; ok {
+			parent.Children = append(parent.Children, c)
+		}
+	}
+}
+
+// collectDescendantIDs 收集指定评论的所有后代评论ID
+func collectDescendantIDs(id uint, thread []*entity.Comment) []uint {
+	var ids []uint
+	for _, c := range thread {
+		if c.ParentID == id {
+			ids = append(ids, c.ID)
+			ids = append(ids, collectDescendantIDs(c.ID, thread)...)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"blog/internal/global"
+	"blog/internal/utils"
+	"blog/model/entity"
+
+	"gorm.io/gorm"
+)
+
+// bufferedCounter Redis缓冲计数器
+// 计数增量先累加在Redis哈希中，再由后台任务批量回写到articles表的对应列
+type bufferedCounter struct {
+	key    string // 增量哈希key: 文章ID -> 未回写的增量
+	column string // articles表中对应的计数列
+}
+
+var (
+	likeCounter = &bufferedCounter{key: "article_like_delta", column: "like_count"}
+	viewCounter = &bufferedCounter{key: "article_view_delta", column: "view_count"}
+)
+
+// flushingKey 回写过程中使用的临时key
+func (c *bufferedCounter) flushingKey() string {
+	return c.key + ":flushing"
+}
+
+// incr 累加指定文章的计数增量
+func (c *bufferedCounter) incr(ctx context.Context, articleID uint, n int64) error {
+	return global.Redis.HIncrBy(ctx, c.key, strconv.FormatUint(uint64(articleID), 10), n).Err()
+}
+
+// pending 获取指定文章尚未回写到数据库的增量
+func (c *bufferedCounter) pending(ctx context.Context, ids []uint) map[uint]int64 {
+	result := make(map[uint]int64, len(ids))
+	if len(ids) == 0 {
+		return result
+	}
+
+	fields := make([]string, 0, len(ids))
+	for _, id := range ids {
+		fields = append(fields, strconv.FormatUint(uint64(id), 10))
+	}
+
+	// 正在回写的增量同样计入，保证回写期间计数不回退
+	for _, key := range []string{c.key, c.flushingKey()} {
+		values, err := global.Redis.HMGet(ctx, key, fields...).Result()
+		if err != nil {
+			continue
+		}
+		for i, v := range values {
+			s, ok := v.(string)
+			if !ok {
+				continue
+			}
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				continue
+			}
+			result[ids[i]] += n
+		}
+	}
+
+	return result
+}
+
+// flush 将Redis中的增量批量回写到数据库
+func (c *bufferedCounter) flush(ctx context.Context) error {
+	flushingKey := c.flushingKey()
+	deltas, err := claimFlushing(ctx, c.key, flushingKey)
+	if err != nil || len(deltas) == 0 {
+		return err
+	}
+
+	for field, value := range deltas {
+		// 先删除字段再回写，字段已被删除时说明增量已处理过，保证同一增量不会被重复累加
+		n, err := global.Redis.HDel(ctx, flushingKey, field).Result()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			continue
+		}
+
+		id, err1 := strconv.ParseUint(field, 10, 64)
+		delta, err2 := strconv.ParseInt(value, 10, 64)
+		if err1 != nil || err2 != nil || delta == 0 {
+			continue
+		}
+
+		err = global.DB.Model(&entity.Article{}).
+			Where("id = ?", id).
+			UpdateColumn(c.column, gorm.Expr("GREATEST("+c.column+" + ?, 0)", delta)).Error
+		if err != nil {
+			// 回写失败时将增量放回，等待下次回写
+			global.Redis.HIncrBy(ctx, c.key, field, delta)
+			return err
+		}
+	}
+
+	return global.Redis.Del(ctx, flushingKey).Err()
+}
+
+// claimFlushing 获取待回写的数据
+// 上次回写未完成时先处理遗留数据，否则将当前数据整体转移到临时key，回写期间的新数据写入原key
+func claimFlushing(ctx context.Context, key, flushingKey string) (map[string]string, error) {
+	exists, err := global.Redis.Exists(ctx, flushingKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		n, err := global.Redis.Exists(ctx, key).Result()
+		if err != nil || n == 0 {
+			return nil, err
+		}
+		if err := global.Redis.Rename(ctx, key, flushingKey).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return global.Redis.HGetAll(ctx, flushingKey).Result()
+}
+
+// FlushCounters 将所有缓冲计数和点赞记录回写到数据库，多实例部署时通过Redis锁保证同一时间只有一个实例回写
+func FlushCounters(ctx context.Context) error {
+	unlock, ok, err := utils.TryLock(ctx, "lock:counter_flush", time.Minute)
+	if err != nil || !ok {
+		return err
+	}
+	defer unlock()
+
+	var firstErr error
+	for _, counter := range []*bufferedCounter{likeCounter, viewCounter} {
+		if err := counter.flush(ctx); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("flush %s: %v", counter.column, err)
+		}
+	}
+	if err := flushLikeChanges(ctx); err != nil && firstErr == nil {
+		firstErr = fmt.Errorf("flush article likes: %v", err)
+	}
+	return firstErr
+}
+
+// applyPendingCounts 将尚未回写的计数合并到文章数据中
+func applyPendingCounts(ctx context.Context, articles ...*entity.Article) {
+	if len(articles) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(articles))
+	for _, a := range articles {
+		ids = append(ids, a.ID)
+	}
+
+	likes := likeCounter.pending(ctx, ids)
+	views := viewCounter.pending(ctx, ids)
+	for _, a := range articles {
+		a.LikeCount += int(likes[a.ID])
+		if a.LikeCount < 0 {
+			a.LikeCount = 0
+		}
+		a.ViewCount += int(views[a.ID])
+	}
+}
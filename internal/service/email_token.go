@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog/internal/global"
+	"blog/internal/mail"
+	"blog/internal/utils"
+	"blog/model/entity"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 邮件令牌用途
+const (
+	purposeVerifyEmail   = "verify_email"
+	purposeResetPassword = "reset_password"
+)
+
+// consumeOnceScript 原子地读取并删除key，保证令牌只能使用一次
+var consumeOnceScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if value then
+	redis.call("DEL", KEYS[1])
+end
+return value
+`)
+
+// emailTokenKey 令牌仅以哈希形式保存在Redis中，值为签发时的邮箱地址
+func emailTokenKey(purpose, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("email_token:%s:%s", purpose, hex.EncodeToString(sum[:]))
+}
+
+// latestEmailTokenKey 用户最近一次签发的令牌，重新签发时旧令牌作废
+func latestEmailTokenKey(purpose string, userID uint) string {
+	return fmt.Sprintf("email_token_latest:%s:%d", purpose, userID)
+}
+
+// issueEmailToken 签发一次性邮件令牌，格式为 用户ID.随机数.签名
+func issueEmailToken(ctx context.Context, purpose string, user *entity.User, ttl time.Duration) (string, error) {
+	nonce, err := utils.RandomString(16)
+	if err != nil {
+		return "", err
+	}
+	payload := fmt.Sprintf("%d.%s", user.ID, nonce)
+	token := payload + "." + utils.Sign(purpose+"|"+payload)
+	key := emailTokenKey(purpose, token)
+	latestKey := latestEmailTokenKey(purpose, user.ID)
+
+	previous, err := global.Redis.Get(ctx, latestKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+
+	_, err = global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previous != "" {
+			pipe.Del(ctx, previous)
+		}
+		pipe.Set(ctx, key, user.Email, ttl)
+		pipe.Set(ctx, latestKey, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeEmailToken 校验签名并使令牌失效，返回令牌所属用户ID和签发时的邮箱
+func consumeEmailToken(ctx context.Context, purpose, token string) (uint, string, error) {
+	invalid := errors.New("链接无效或已过期")
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, "", invalid
+	}
+	payload := parts[0] + "." + parts[1]
+	if !utils.VerifySign(purpose+"|"+payload, parts[2]) {
+		return 0, "", invalid
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, "", invalid
+	}
+
+	key := emailTokenKey(purpose, token)
+	email, err := consumeOnceScript.Run(ctx, global.Redis, []string{key}).Text()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, "", invalid
+		}
+		return 0, "", err
+	}
+	global.Redis.Del(ctx, latestEmailTokenKey(purpose, uint(userID)))
+
+	return uint(userID), email, nil
+}
+
+// allowMail 限制同一用户发送同类邮件的频率
+func allowMail(ctx context.Context, purpose string, userID uint) (bool, error) {
+	interval := time.Duration(global.Config.Account.MailInterval) * time.Second
+	if interval <= 0 {
+		return true, nil
+	}
+	return global.Redis.SetNX(ctx, fmt.Sprintf("mail_throttle:%s:%d", purpose, userID), 1, interval).Result()
+}
+
+// sendVerificationEmail 发送邮箱验证邮件
+func sendVerificationEmail(ctx context.Context, user *entity.User) error {
+	ttl := time.Duration(global.Config.Account.VerifyTokenTTL) * time.Minute
+	token, err := issueEmailToken(ctx, purposeVerifyEmail, user, ttl)
+	if err != nil {
+		return err
+	}
+
+	site := global.Config.Site
+	link := fmt.Sprintf("%s/verify-email?token=%s", strings.TrimRight(site.URL, "/"), url.QueryEscape(token))
+	return global.Mailer.Send(ctx, &mail.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("【%s】请验证您的邮箱", site.Title),
+		Body: fmt.Sprintf("%s，您好：\n\n请在%d分钟内点击以下链接完成邮箱验证：\n%s\n\n如果这不是您本人的操作，请忽略此邮件。\n",
+			user.Nickname, int(ttl.Minutes()), link),
+	})
+}
+
+// sendPasswordResetEmail 发送密码重置邮件
+func sendPasswordResetEmail(ctx context.Context, user *entity.User) error {
+	ttl := time.Duration(global.Config.Account.ResetTokenTTL) * time.Minute
+	token, err := issueEmailToken(ctx, purposeResetPassword, user, ttl)
+	if err != nil {
+		return err
+	}
+
+	site := global.Config.Site
+	link := fmt.Sprintf("%s/reset-password?token=%s", strings.TrimRight(site.URL, "/"), url.QueryEscape(token))
+	return global.Mailer.Send(ctx, &mail.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("【%s】重置密码", site.Title),
+		Body: fmt.Sprintf("%s，您好：\n\n我们收到了重置您账号密码的请求，请在%d分钟内点击以下链接设置新密码：\n%s\n\n如果这不是您本人的操作，请忽略此邮件，您的密码不会被修改。\n",
+			user.Nickname, int(ttl.Minutes()), link),
+	})
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"blog/internal/global"
+	"blog/model/entity"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// feedItemLimit 订阅源中的文章数量
+	feedItemLimit = 20
+	// feedSummaryLength 摘要为空时截取正文的长度
+	feedSummaryLength = 200
+)
+
+type FeedService struct{}
+
+func NewFeedService() *FeedService {
+	return &FeedService{}
+}
+
+// ErrFeedAuthorNotFound 按作者筛选时作者不存在
+var ErrFeedAuthorNotFound = errors.New("作者不存在")
+
+// getDB 获取数据库连接，支持事务
+func (s *FeedService) getDB(ctx context.Context) *gorm.DB {
+	return global.GetDB(ctx)
+}
+
+// GetFeed 获取订阅源数据，支持按作者和标签筛选
+// feedPath 为订阅源自身的路径，用于生成self链接
+func (s *FeedService) GetFeed(ctx context.Context, feedPath string, req *req.FeedRequest) (*resp.Feed, error) {
+	db := s.getDB(ctx)
+	site := global.Config.Site
+	siteURL := strings.TrimRight(site.URL, "/")
+
+	feed := &resp.Feed{
+		Title:       site.Title,
+		Link:        siteURL,
+		FeedURL:     siteURL + feedPath,
+		Description: site.Description,
+	}
+
+	query := db.Model(&entity.Article{}).Where("status = ?", 1)
+
+	if req.AuthorID != 0 {
+		var author entity.User
+		if err := db.First(&author, req.AuthorID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrFeedAuthorNotFound
+			}
+			return nil, err
+		}
+		query = query.Where("author_id = ?", req.AuthorID)
+		feed.Title = fmt.Sprintf("%s - %s", site.Title, author.Nickname)
+	}
+	if req.Tag != "" {
+		query = query.Where("id IN (?)", db.Table("article_tags").
+			Select("article_tags.article_id").
+			Joins("JOIN tags ON tags.id = article_tags.tag_id").
+			Where("tags.name = ?", req.Tag))
+		feed.Title = fmt.Sprintf("%s - #%s", feed.Title, req.Tag)
+	}
+
+	var articles []*entity.Article
+	if err := query.Preload("Author").Preload("TagList").
+		Order("published_at DESC").
+		Limit(feedItemLimit).
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	for _, article := range articles {
+		item := &resp.FeedItem{
+			ID:        article.ID,
+			Title:     article.Title,
+			Link:      fmt.Sprintf("%s/articles/%s", siteURL, article.Slug),
+			Summary:   feedSummary(article),
+			Content:   article.Content,
+			UpdatedAt: article.UpdatedAt,
+		}
+		if article.PublishedAt != nil {
+			item.PublishedAt = *article.PublishedAt
+		} else {
+			item.PublishedAt = article.CreatedAt
+		}
+		if article.Author != nil {
+			item.Author = article.Author.Nickname
+		}
+		for _, tag := range article.TagList {
+			item.Tags = append(item.Tags, tag.Name)
+		}
+
+		// 订阅源的更新时间取文章最近的发布或修改时间
+		if item.UpdatedAt.After(feed.Updated) {
+			feed.Updated = item.UpdatedAt
+		}
+		if item.PublishedAt.After(feed.Updated) {
+			feed.Updated = item.PublishedAt
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Unix(0, 0)
+	}
+
+	return feed, nil
+}
+
+// feedSummary 获取文章摘要，摘要为空时截取正文
+func feedSummary(article *entity.Article) string {
+	if article.Summary != "" {
+		return article.Summary
+	}
+
+	content := []rune(strings.TrimSpace(article.Content))
+	if len(content) <= feedSummaryLength {
+		return string(content)
+	}
+	return string(content[:feedSummaryLength]) + "..."
+}
@@ -2,38 +2,28 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"mime/multipart"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"blog/internal/global"
+	"blog/internal/rbac"
+	"blog/internal/utils"
 	"blog/model/entity"
+	"blog/model/req"
 	"blog/model/resp"
 
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
 	"gorm.io/gorm"
 )
 
-type FileService struct {
-	minioClient *minio.Client
-}
+type FileService struct{}
 
 func NewFileService() *FileService {
-	// 初始化MinIO客户端
-	minioClient, err := InitMinio()
-	if err != nil {
-		// 如果MinIO初始化失败，记录错误但不终止程序
-		fmt.Printf("Warning: Failed to initialize MinIO: %v\n", err)
-		minioClient = nil
-	}
-
-	return &FileService{
-		minioClient: minioClient,
-	}
+	return &FileService{}
 }
 
 // getDB 获取数据库连接，支持事务
@@ -41,120 +31,9 @@ func (s *FileService) getDB(ctx context.Context) *gorm.DB {
 	return global.GetDB(ctx)
 }
 
-// InitMinio 初始化MinIO客户端
-func InitMinio() (*minio.Client, error) {
-	cfg := global.Config
-	if cfg == nil {
-		return nil, fmt.Errorf("config not initialized")
-	}
-
-	minioClient, err := minio.New(cfg.Minio.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.Minio.AccessKeyID, cfg.Minio.SecretAccessKey, ""),
-		Secure: cfg.Minio.UseSSL,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	// 检查并创建bucket
-	ctx := context.Background()
-	exists, err := minioClient.BucketExists(ctx, cfg.Minio.BucketName)
-	if err != nil {
-		return nil, err
-	}
-
-	if !exists {
-		err = minioClient.MakeBucket(ctx, cfg.Minio.BucketName, minio.MakeBucketOptions{})
-		if err != nil {
-			return nil, err
-		}
-
-		// 设置bucket为公共读取权限
-		policy := `{
-			"Version": "2012-10-17",
-			"Statement": [
-				{
-					"Effect": "Allow",
-					"Principal": {"AWS": ["*"]},
-					"Action": ["s3:GetObject"],
-					"Resource": ["arn:aws:s3:::` + cfg.Minio.BucketName + `/*"]
-				}
-			]
-		}`
-
-		err = minioClient.SetBucketPolicy(ctx, cfg.Minio.BucketName, policy)
-		if err != nil {
-			fmt.Printf("Warning: Failed to set bucket policy: %v\n", err)
-			// 不返回错误，因为bucket已经创建成功
-		}
-	} else {
-		// 如果bucket已存在，检查并设置策略
-		policy := `{
-			"Version": "2012-10-17",
-			"Statement": [
-				{
-					"Effect": "Allow",
-					"Principal": {"AWS": ["*"]},
-					"Action": ["s3:GetObject"],
-					"Resource": ["arn:aws:s3:::` + cfg.Minio.BucketName + `/*"]
-				}
-			]
-		}`
-
-		err = minioClient.SetBucketPolicy(ctx, cfg.Minio.BucketName, policy)
-		if err != nil {
-			fmt.Printf("Warning: Failed to set bucket policy for existing bucket: %v\n", err)
-		}
-	}
-
-	return minioClient, nil
-}
-
-// SetBucketPublicPolicy 设置bucket为公共读取权限
-// 可以用于修复已存在的bucket权限问题
-func SetBucketPublicPolicy() error {
-	cfg := global.Config
-	if cfg == nil {
-		return fmt.Errorf("config not initialized")
-	}
-
-	minioClient, err := minio.New(cfg.Minio.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.Minio.AccessKeyID, cfg.Minio.SecretAccessKey, ""),
-		Secure: cfg.Minio.UseSSL,
-	})
-	if err != nil {
-		return err
-	}
-
-	policy := `{
-		"Version": "2012-10-17",
-		"Statement": [
-			{
-				"Effect": "Allow",
-				"Principal": {"AWS": ["*"]},
-				"Action": ["s3:GetObject"],
-				"Resource": ["arn:aws:s3:::` + cfg.Minio.BucketName + `/*"]
-			}
-		]
-	}`
-
-	ctx := context.Background()
-	err = minioClient.SetBucketPolicy(ctx, cfg.Minio.BucketName, policy)
-	if err != nil {
-		return fmt.Errorf("failed to set bucket policy: %v", err)
-	}
-
-	fmt.Printf("Successfully set public read policy for bucket: %s\n", cfg.Minio.BucketName)
-	return nil
-}
-
 // UploadFile 上传文件
+// 需在事务中调用：先保存到存储再写入数据库，写入失败时删除已保存的对象
 func (s *FileService) UploadFile(ctx context.Context, userID uint, fileHeader *multipart.FileHeader) (*resp.FileUploadResponse, error) {
-	// 检查MinIO客户端是否可用
-	if s.minioClient == nil {
-		return nil, fmt.Errorf("文件上传服务不可用，MinIO未正确配置")
-	}
-
 	db := s.getDB(ctx)
 
 	// 打开文件
@@ -164,32 +43,26 @@ func (s *FileService) UploadFile(ctx context.Context, userID uint, fileHeader *m
 	}
 	defer file.Close()
 
-	// 生成文件名
+	// 按文件内容检测类型，校验允许类型、扩展名和大小
 	ext := filepath.Ext(fileHeader.Filename)
-	dateStr := time.Now().Format("2006-01-02")
-	fileName := fmt.Sprintf("%d_%d%s", userID, time.Now().Unix(), ext)
-	dirPath := fmt.Sprintf("uploads/%s", dateStr)
-	filePath := fmt.Sprintf("%s/%s", dirPath, fileName)
-
-	// 确保目录存在
-	if err := os.MkdirAll(dirPath, os.ModePerm); err != nil {
+	fileType, err := checkUploadType(file, ext, fileHeader.Size)
+	if err != nil {
 		return nil, err
 	}
 
-	// 获取文件类型
-	fileType := getFileType(ext)
-
-	// 上传到MinIO
-	cfg := global.Config
-	_, err = s.minioClient.PutObject(ctx, cfg.Minio.BucketName, filePath, file, fileHeader.Size, minio.PutObjectOptions{
-		ContentType: fileType,
-	})
+	// 生成文件名，随机后缀避免同一秒内上传的文件重名
+	suffix, err := utils.RandomString(4)
 	if err != nil {
 		return nil, err
 	}
+	now := time.Now()
+	fileName := fmt.Sprintf("%d_%d_%s%s", userID, now.Unix(), suffix, ext)
+	filePath := fmt.Sprintf("uploads/%s/%s", now.Format("2006-01-02"), fileName)
 
-	// 生成文件URL
-	fileURL := s.generateFileURL(filePath)
+	// 保存到存储
+	if err := global.Storage.Put(ctx, filePath, file, fileHeader.Size, fileType); err != nil {
+		return nil, err
+	}
 
 	// 保存文件记录到数据库
 	fileRecord := &entity.File{
@@ -198,12 +71,15 @@ func (s *FileService) UploadFile(ctx context.Context, userID uint, fileHeader *m
 		FileSize:     fileHeader.Size,
 		FileType:     fileType,
 		FilePath:     filePath,
-		FileURL:      fileURL,
+		FileURL:      global.Storage.URL(filePath),
 		UploaderID:   userID,
 		Status:       1,
 	}
 
 	if err := db.Create(fileRecord).Error; err != nil {
+		if delErr := global.Storage.Delete(ctx, filePath); delErr != nil {
+			log.Printf("Failed to remove orphaned upload %s: %v", filePath, delErr)
+		}
 		return nil, err
 	}
 
@@ -227,60 +103,167 @@ func (s *FileService) GetFileByID(ctx context.Context, id uint) (*entity.File, e
 	return &file, nil
 }
 
+// GetFiles 分页获取文件列表，支持按上传者、类型和上传日期筛选
+func (s *FileService) GetFiles(ctx context.Context, req *req.FileListRequest) (*resp.FileListResponse, error) {
+	db := s.getDB(ctx)
+
+	var files []entity.File
+	var total int64
+
+	query := db.Model(&entity.File{})
+	if req.UploaderID != 0 {
+		query = query.Where("uploader_id = ?", req.UploaderID)
+	}
+	if req.Type != "" {
+		if strings.Contains(req.Type, "/") {
+			query = query.Where("file_type = ?", req.Type)
+		} else {
+			query = query.Where("file_type LIKE ?", req.Type+"/%")
+		}
+	}
+	if req.StartDate != "" {
+		start, err := time.ParseInLocation("2006-01-02", req.StartDate, time.Local)
+		if err != nil {
+			return nil, errors.New("起始日期格式错误")
+		}
+		query = query.Where("created_at >= ?", start)
+	}
+	if req.EndDate != "" {
+		end, err := time.ParseInLocation("2006-01-02", req.EndDate, time.Local)
+		if err != nil {
+			return nil, errors.New("截止日期格式错误")
+		}
+		query = query.Where("created_at < ?", end.AddDate(0, 0, 1))
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.Order("created_at DESC").Order("id DESC").Offset(offset).Limit(req.PageSize).Find(&files).Error; err != nil {
+		return nil, err
+	}
+
+	return &resp.FileListResponse{
+		Files:    files,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, nil
+}
+
+// GetFile 获取文件信息，仅上传者或有管理文件权限的用户可查看
+func (s *FileService) GetFile(ctx context.Context, id, userID uint) (*entity.File, error) {
+	file, err := s.getAuthorizedFile(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// RenameFile 修改文件显示名称，不影响存储路径和访问URL
+func (s *FileService) RenameFile(ctx context.Context, id, userID uint, name string) (*entity.File, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("文件名不能为空")
+	}
+	if strings.ContainsAny(name, "/\\\x00") {
+		return nil, errors.New("文件名不能包含路径分隔符")
+	}
+
+	file, err := s.getAuthorizedFile(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.getDB(ctx).Model(file).Update("original_name", name).Error; err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
 // DeleteFile 删除文件
+// 需在事务中调用：先删除数据库记录再删除存储对象，对象删除失败时回滚，避免记录指向不存在的文件
 func (s *FileService) DeleteFile(ctx context.Context, id, userID uint) error {
+	file, err := s.getAuthorizedFile(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	// 从数据库删除记录
+	if err := s.getDB(ctx).Delete(file).Error; err != nil {
+		return err
+	}
+
+	// 从存储删除文件
+	return global.Storage.Delete(ctx, file.FilePath)
+}
+
+// getAuthorizedFile 获取文件并检查当前用户是否为上传者或有管理文件权限
+func (s *FileService) getAuthorizedFile(ctx context.Context, id, userID uint) (*entity.File, error) {
 	db := s.getDB(ctx)
 
 	var file entity.File
 	if err := db.First(&file, id).Error; err != nil {
-		return err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("文件不存在")
+		}
+		return nil, err
 	}
 
 	// 检查权限
-	if file.UploaderID != userID {
-		return fmt.Errorf("无权限删除此文件")
-	}
-
-	// 从MinIO删除文件
-	cfg := global.Config
-	err := s.minioClient.RemoveObject(ctx, cfg.Minio.BucketName, file.FilePath, minio.RemoveObjectOptions{})
+	allowed, err := authorize(ctx, db, userID, file.UploaderID, rbac.PermFileModerate)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if !allowed {
+		return nil, errors.New("无权限操作此文件")
+	}
+	return &file, nil
+}
 
-	// 从数据库删除记录
-	return db.Delete(&file).Error
+// MaxUploadSize 允许上传的最大文件大小(字节)，取各类型上限的最大值
+func (s *FileService) MaxUploadSize() int64 {
+	var maxSize int64
+	for _, t := range global.Config.Upload.Types {
+		if t.MaxSize > maxSize {
+			maxSize = t.MaxSize
+		}
+	}
+	return maxSize * 1024 * 1024
 }
 
-// generateFileURL 生成文件访问URL
-func (s *FileService) generateFileURL(filePath string) string {
-	cfg := global.Config
-	protocol := "http"
-	if cfg.Minio.UseSSL {
-		protocol = "https"
+// checkUploadType 按文件内容检测MIME类型，返回检测到的类型
+// 类型不在允许列表、扩展名与内容不符或超过该类型的大小上限时拒绝
+// 使用ReadAt读取，不改变文件的读取位置
+func checkUploadType(file multipart.File, ext string, size int64) (string, error) {
+	contentType, err := utils.DetectContentType(file, size, ext)
+	if err != nil {
+		return "", err
 	}
-	return fmt.Sprintf("%s://%s/%s/%s", protocol, cfg.Minio.Endpoint, cfg.Minio.BucketName, filePath)
+	for _, t := range global.Config.Upload.Types {
+		if t.MIME != contentType {
+			continue
+		}
+		if !containsFold(t.Extensions, ext) {
+			return "", fmt.Errorf("文件扩展名与内容不符，%s文件的扩展名应为%s", contentType, strings.Join(t.Extensions, "、"))
+		}
+		if size > t.MaxSize*1024*1024 {
+			return "", fmt.Errorf("%s文件大小不能超过%dMB", contentType, t.MaxSize)
+		}
+		return contentType, nil
+	}
+	return "", fmt.Errorf("不支持上传该类型的文件: %s", contentType)
 }
 
-// getFileType 根据文件扩展名获取文件类型
-func getFileType(ext string) string {
-	ext = strings.ToLower(ext)
-	switch ext {
-	case ".jpg", ".jpeg":
-		return "image/jpeg"
-	case ".png":
-		return "image/png"
-	case ".gif":
-		return "image/gif"
-	case ".pdf":
-		return "application/pdf"
-	case ".doc":
-		return "application/msword"
-	case ".docx":
-		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
-	case ".txt":
-		return "text/plain"
-	default:
-		return "application/octet-stream"
+// containsFold 判断切片是否包含指定字符串，忽略大小写
+func containsFold(items []string, target string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item, target) {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"blog/internal/global"
+	"blog/internal/utils"
+	"blog/model/entity"
+	"blog/model/resp"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// likeScript 原子地记录点赞用户、累加点赞增量并记录待回写的点赞变更，重复点赞不重复计数
+var likeScript = redis.NewScript(`
+if redis.call("SADD", KEYS[1], ARGV[1]) == 1 then
+	redis.call("HINCRBY", KEYS[2], ARGV[2], 1)
+	redis.call("HSET", KEYS[3], ARGV[3], 1)
+	return 1
+end
+return 0
+`)
+
+// unlikeScript 原子地移除点赞用户、扣减点赞增量并记录待回写的点赞变更，未点赞时不重复扣减
+var unlikeScript = redis.NewScript(`
+if redis.call("SREM", KEYS[1], ARGV[1]) == 1 then
+	redis.call("HINCRBY", KEYS[2], ARGV[2], -1)
+	redis.call("HSET", KEYS[3], ARGV[3], 0)
+	return 1
+end
+return 0
+`)
+
+// likeChangesKey 尚未回写到article_likes表的点赞变更: 文章ID:用户ID -> 1点赞/0取消点赞
+// 只保留最后一次变更，回写是幂等的
+const likeChangesKey = "article_like_changes"
+
+// likeUsersPlaceholder 点赞用户集合的占位成员，保证没有点赞的文章也能缓存空集合
+const likeUsersPlaceholder = "0"
+
+// likeUsersLoadBatch 从数据库加载点赞用户时每批写入Redis的数量
+const likeUsersLoadBatch = 1000
+
+type LikeService struct{}
+
+func NewLikeService() *LikeService {
+	return &LikeService{}
+}
+
+// getDB 获取数据库连接，支持事务
+func (s *LikeService) getDB(ctx context.Context) *gorm.DB {
+	return global.GetDB(ctx)
+}
+
+// likeUsersKey 文章点赞用户集合key
+func likeUsersKey(articleID uint) string {
+	return fmt.Sprintf("article_likes:%d", articleID)
+}
+
+// likeChangeField 点赞变更字段
+func likeChangeField(articleID, userID uint) string {
+	return fmt.Sprintf("%d:%d", articleID, userID)
+}
+
+// LikeArticle 点赞文章，重复点赞幂等
+func (s *LikeService) LikeArticle(ctx context.Context, articleID, userID uint) (*resp.ArticleLikeResponse, error) {
+	article, err := s.getPublishedArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadLikeUsers(ctx, articleID); err != nil {
+		return nil, err
+	}
+
+	keys := []string{likeUsersKey(articleID), likeCounter.key, likeChangesKey}
+	args := []interface{}{userID, articleID, likeChangeField(articleID, userID)}
+	if err := likeScript.Run(ctx, global.Redis, keys, args...).Err(); err != nil {
+		return nil, err
+	}
+
+	return s.likeResponse(ctx, article, true), nil
+}
+
+// UnlikeArticle 取消点赞，未点赞时幂等
+func (s *LikeService) UnlikeArticle(ctx context.Context, articleID, userID uint) (*resp.ArticleLikeResponse, error) {
+	article, err := s.getPublishedArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadLikeUsers(ctx, articleID); err != nil {
+		return nil, err
+	}
+
+	keys := []string{likeUsersKey(articleID), likeCounter.key, likeChangesKey}
+	args := []interface{}{userID, articleID, likeChangeField(articleID, userID)}
+	if err := unlikeScript.Run(ctx, global.Redis, keys, args...).Err(); err != nil {
+		return nil, err
+	}
+
+	return s.likeResponse(ctx, article, false), nil
+}
+
+// loadLikeUsers Redis中没有文章的点赞用户集合时从数据库加载
+// 先写入临时key再RENAMENX，避免覆盖加载期间其他请求已建立的集合
+func (s *LikeService) loadLikeUsers(ctx context.Context, articleID uint) error {
+	key := likeUsersKey(articleID)
+	n, err := global.Redis.Exists(ctx, key).Result()
+	if err != nil || n > 0 {
+		return err
+	}
+
+	var userIDs []uint
+	if err := s.getDB(ctx).Model(&entity.ArticleLike{}).
+		Where("article_id = ?", articleID).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	suffix, err := utils.RandomString(8)
+	if err != nil {
+		return err
+	}
+	tmpKey := fmt.Sprintf("%s:loading:%s", key, suffix)
+	defer global.Redis.Del(ctx, tmpKey)
+
+	members := []interface{}{likeUsersPlaceholder}
+	for _, id := range userIDs {
+		members = append(members, id)
+		if len(members) >= likeUsersLoadBatch {
+			if err := global.Redis.SAdd(ctx, tmpKey, members...).Err(); err != nil {
+				return err
+			}
+			members = members[:0]
+		}
+	}
+	if len(members) > 0 {
+		if err := global.Redis.SAdd(ctx, tmpKey, members...).Err(); err != nil {
+			return err
+		}
+	}
+
+	return global.Redis.RenameNX(ctx, tmpKey, key).Err()
+}
+
+// flushLikeChanges 将Redis中的点赞变更回写到article_likes表
+// 变更记录的是最终状态，重复回写不会产生重复数据
+func flushLikeChanges(ctx context.Context) error {
+	flushingKey := likeChangesKey + ":flushing"
+	changes, err := claimFlushing(ctx, likeChangesKey, flushingKey)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+
+	for field, value := range changes {
+		articleID, userID, ok := parseLikeChangeField(field)
+		if ok {
+			like := entity.ArticleLike{UserID: userID, ArticleID: articleID}
+			db := global.DB
+			if value == "1" {
+				err = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&like).Error
+			} else {
+				err = db.Where("user_id = ? AND article_id = ?", userID, articleID).Delete(&entity.ArticleLike{}).Error
+			}
+			if err != nil {
+				// 保留剩余数据，等待下次回写
+				return err
+			}
+		}
+
+		global.Redis.HDel(ctx, flushingKey, field)
+	}
+
+	return global.Redis.Del(ctx, flushingKey).Err()
+}
+
+// parseLikeChangeField 解析点赞变更字段中的文章ID和用户ID
+func parseLikeChangeField(field string) (articleID, userID uint, ok bool) {
+	a, u, found := strings.Cut(field, ":")
+	if !found {
+		return 0, 0, false
+	}
+	aid, err1 := strconv.ParseUint(a, 10, 64)
+	uid, err2 := strconv.ParseUint(u, 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return uint(aid), uint(uid), true
+}
+
+// getPublishedArticle 获取已发布的文章
+func (s *LikeService) getPublishedArticle(ctx context.Context, articleID uint) (*entity.Article, error) {
+	db := s.getDB(ctx)
+
+	var article entity.Article
+	if err := db.First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("文章不存在")
+		}
+		return nil, err
+	}
+	if article.Status != 1 {
+		return nil, errors.New("文章不存在")
+	}
+	return &article, nil
+}
+
+// likeResponse 构建点赞响应，点赞数包含尚未回写的增量
+func (s *LikeService) likeResponse(ctx context.Context, article *entity.Article, liked bool) *resp.ArticleLikeResponse {
+	applyPendingCounts(ctx, article)
+	return &resp.ArticleLikeResponse{
+		ArticleID: article.ID,
+		Liked:     liked,
+		LikeCount: article.LikeCount,
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"blog/internal/global"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 登录失败计数的维度
+const (
+	loginScopeAccount = "account"
+	loginScopeIP      = "ip"
+)
+
+// errInvalidCredentials 用户不存在和密码错误使用相同的提示，避免通过登录接口枚举用户名
+var errInvalidCredentials = errors.New("用户名或密码错误")
+
+// recordLoginFailureScript 记录一次登录失败，返回窗口内的失败次数
+// 失败次数达到上限时锁定，超过免等待次数后按指数退避设置下次允许登录的时间
+// KEYS[1] 失败计数 KEYS[2] 登录限制
+// ARGV: 统计窗口(秒) 免等待次数 退避初始时间(秒) 退避最长时间(秒) 锁定阈值 锁定时长(秒)
+var recordLoginFailureScript = redis.NewScript(`
+local failures = redis.call("INCR", KEYS[1])
+redis.call("EXPIRE", KEYS[1], ARGV[1])
+if tonumber(ARGV[5]) > 0 and tonumber(ARGV[6]) > 0 and failures >= tonumber(ARGV[5]) then
+	redis.call("SET", KEYS[2], "lock", "EX", ARGV[6])
+	return failures
+end
+local over = failures - tonumber(ARGV[2])
+if over > 0 then
+	local delay = math.min(tonumber(ARGV[3]) * 2 ^ (over - 1), tonumber(ARGV[4]))
+	if delay >= 1 then
+		redis.call("SET", KEYS[2], "backoff", "EX", math.floor(delay))
+	end
+end
+return failures
+`)
+
+// dummyPasswordHash 用户不存在时同样执行一次bcrypt比较，避免通过响应时间枚举用户名
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// loginFailKey 登录失败计数
+func loginFailKey(scope, id string) string {
+	return fmt.Sprintf("login_fail:%s:%s", scope, id)
+}
+
+// loginBlockKey 登录限制，值为backoff(退避)或lock(锁定)，过期后恢复登录
+func loginBlockKey(scope, id string) string {
+	return fmt.Sprintf("login_block:%s:%s", scope, id)
+}
+
+// loginAccountID 登录账号标识，用户存在时按用户ID计数，用户名和邮箱登录共用同一计数
+// 用户不存在时按登录名计数，使不存在的账号与存在的账号表现一致
+func loginAccountID(userID uint, username string) string {
+	if userID != 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	return "name:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+type loginScope struct {
+	name string
+	id   string
+}
+
+// loginScopes 需要计数的维度，IP为空时只按账号计数
+func loginScopes(account, ip string) []loginScope {
+	scopes := []loginScope{{loginScopeAccount, account}}
+	if ip != "" {
+		scopes = append(scopes, loginScope{loginScopeIP, ip})
+	}
+	return scopes
+}
+
+// checkLoginAllowed 检查账号和IP当前是否允许尝试登录
+func checkLoginAllowed(ctx context.Context, account, ip string) error {
+	for _, scope := range loginScopes(account, ip) {
+		key := loginBlockKey(scope.name, scope.id)
+		state, err := global.Redis.Get(ctx, key).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		ttl, err := global.Redis.TTL(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if ttl <= 0 {
+			continue
+		}
+
+		if state == "lock" {
+			minutes := int(math.Ceil(ttl.Minutes()))
+			return fmt.Errorf("登录失败次数过多，请%d分钟后重试", minutes)
+		}
+		seconds := int(math.Ceil(ttl.Seconds()))
+		return fmt.Errorf("登录尝试过于频繁，请%d秒后重试", seconds)
+	}
+	return nil
+}
+
+// recordLoginFailure 记录账号和IP的登录失败
+func recordLoginFailure(ctx context.Context, account, ip string) error {
+	cfg := global.Config.Login
+	window := time.Duration(cfg.WindowMinute) * time.Minute
+	lockout := time.Duration(cfg.LockoutMinute) * time.Minute
+
+	for _, scope := range loginScopes(account, ip) {
+		maxFailures := cfg.MaxAccountFailures
+		if scope.name == loginScopeIP {
+			maxFailures = cfg.MaxIPFailures
+		}
+		err := recordLoginFailureScript.Run(ctx, global.Redis,
+			[]string{loginFailKey(scope.name, scope.id), loginBlockKey(scope.name, scope.id)},
+			int64(window.Seconds()), cfg.FreeAttempts, cfg.BackoffBase, cfg.BackoffMax,
+			maxFailures, int64(lockout.Seconds()),
+		).Err()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearLoginFailures 清除账号的登录失败计数和限制，IP计数不因某个账号登录成功而清除
+func clearLoginFailures(ctx context.Context, account string) error {
+	return global.Redis.Del(ctx,
+		loginFailKey(loginScopeAccount, account),
+		loginBlockKey(loginScopeAccount, account),
+	).Err()
+}
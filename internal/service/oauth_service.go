@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"blog/internal/global"
+	"blog/internal/oauth"
+	"blog/internal/rbac"
+	"blog/internal/utils"
+	"blog/model/entity"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// OAuthStateTTL 授权state的有效期
+const OAuthStateTTL = 10 * time.Minute
+
+// oauthStateKey 授权state，值为发起授权的提供方名称和浏览器nonce的哈希
+func oauthStateKey(state string) string {
+	return "oauth_state:" + state
+}
+
+// oauthStateValue 授权state对应的值
+func oauthStateValue(provider, nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return provider + ":" + hex.EncodeToString(sum[:])
+}
+
+type OAuthService struct{}
+
+func NewOAuthService() *OAuthService {
+	return &OAuthService{}
+}
+
+// getDB 获取数据库连接，支持事务
+func (s *OAuthService) getDB(ctx context.Context) *gorm.DB {
+	return global.GetDB(ctx)
+}
+
+// getProvider 获取已启用的提供方
+func (s *OAuthService) getProvider(name string) (oauth.Provider, error) {
+	provider, ok := global.OAuthProviders[name]
+	if !ok {
+		return nil, errors.New("不支持的第三方登录方式")
+	}
+	return provider, nil
+}
+
+// GetProviders 获取已启用的提供方名称
+func (s *OAuthService) GetProviders() []string {
+	names := make([]string, 0, len(global.OAuthProviders))
+	for name := range global.OAuthProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Authorize 生成第三方授权地址，state一次有效
+// 同时生成浏览器nonce，由handler写入发起授权的浏览器cookie，登录时必须提交同一nonce，防止登录CSRF
+func (s *OAuthService) Authorize(ctx context.Context, name string) (*resp.OAuthAuthorizeResponse, error) {
+	provider, err := s.getProvider(name)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := utils.RandomString(16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := utils.RandomString(16)
+	if err != nil {
+		return nil, err
+	}
+	if err := global.Redis.Set(ctx, oauthStateKey(state), oauthStateValue(name, nonce), OAuthStateTTL).Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp.OAuthAuthorizeResponse{
+		AuthorizeURL: provider.AuthCodeURL(state),
+		State:        state,
+		Nonce:        nonce,
+	}, nil
+}
+
+// Login 使用授权码完成第三方登录
+// 已关联的第三方账号直接登录；否则按第三方平台已验证的邮箱关联本地账号，仍无法关联时按配置自动注册
+func (s *OAuthService) Login(ctx context.Context, name string, req *req.OAuthLoginRequest) (*resp.UserLoginResponse, error) {
+	provider, err := s.getProvider(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// state只能使用一次，且必须由同一提供方在同一浏览器中发起
+	value, err := consumeOnceScript.Run(ctx, global.Redis, []string{oauthStateKey(req.State)}).Text()
+	if err != nil || req.Nonce == "" || subtle.ConstantTimeCompare([]byte(value), []byte(oauthStateValue(name, req.Nonce))) != 1 {
+		return nil, errors.New("授权已过期，请重新登录")
+	}
+
+	info, err := provider.Exchange(ctx, req.Code)
+	if err != nil {
+		log.Printf("OAuth exchange with %s failed: %v", name, err)
+		return nil, errors.New("第三方授权失败，请重新登录")
+	}
+
+	user, err := s.findOrCreateUser(ctx, name, info)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查账号状态
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	// 开启两步验证时同样需要完成两步验证
+	if user.TOTPEnabled {
+		challenge, err := newLoginChallenge(ctx, user.ID, req.IP, req.UserAgent)
+		if err != nil {
+			return nil, err
+		}
+		return &resp.UserLoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		}, nil
+	}
+
+	tokens, err := newTokenFamily(ctx, user.ID, req.IP, req.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.UserLoginResponse{
+		TokenResponse: tokens,
+		User:          user,
+	}, nil
+}
+
+// findOrCreateUser 查找第三方账号关联的本地用户，未关联时按邮箱关联或自动注册
+func (s *OAuthService) findOrCreateUser(ctx context.Context, provider string, info *oauth.UserInfo) (*entity.User, error) {
+	db := s.getDB(ctx)
+
+	var account entity.SocialAccount
+	err := db.Where("provider = ? AND provider_user_id = ?", provider, info.ID).First(&account).Error
+	if err == nil {
+		var user entity.User
+		if err := db.First(&user, account.UserID).Error; err != nil {
+			return nil, err
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// 只信任第三方平台已验证的邮箱
+	if info.Email == "" || !info.EmailVerified {
+		return nil, errors.New("无法获取第三方账号已验证的邮箱")
+	}
+
+	var user entity.User
+	err = db.Where("email = ?", info.Email).First(&user).Error
+	switch {
+	case err == nil:
+		// 本地账号邮箱未验证时不关联，避免他人预先用该邮箱注册后接管第三方登录
+		if user.EmailVerifiedAt == nil {
+			return nil, errors.New("该邮箱已注册但尚未验证，请先使用密码登录并完成邮箱验证")
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if !global.Config.OAuth.AllowSignup {
+			return nil, errors.New("该第三方账号未关联本站账号")
+		}
+		created, err := s.createUser(ctx, info)
+		if err != nil {
+			return nil, err
+		}
+		user = *created
+	default:
+		return nil, err
+	}
+
+	account = entity.SocialAccount{
+		UserID:         user.ID,
+		Provider:       provider,
+		ProviderUserID: info.ID,
+		Email:          info.Email,
+	}
+	if err := db.Create(&account).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// createUser 为第三方账号注册本地用户，邮箱视为已验证，密码为随机值
+func (s *OAuthService) createUser(ctx context.Context, info *oauth.UserInfo) (*entity.User, error) {
+	db := s.getDB(ctx)
+
+	username, err := s.uniqueUsername(ctx, info.Username)
+	if err != nil {
+		return nil, err
+	}
+	password, err := utils.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &entity.User{
+		Username:        username,
+		Email:           info.Email,
+		EmailVerifiedAt: &now,
+		Password:        string(hashedPassword),
+		Nickname:        info.Name,
+		Avatar:          info.AvatarURL,
+		Role:            rbac.DefaultRole,
+		Status:          1,
+	}
+	if nickname := []rune(user.Nickname); len(nickname) > 50 {
+		user.Nickname = string(nickname[:50])
+	}
+	if user.Nickname == "" {
+		user.Nickname = username
+	}
+	if len(user.Avatar) > 255 {
+		user.Avatar = ""
+	}
+
+	if err := db.Create(user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// uniqueUsername 根据第三方用户名生成未被占用的本地用户名
+func (s *OAuthService) uniqueUsername(ctx context.Context, name string) (string, error) {
+	db := s.getDB(ctx)
+
+	base := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return -1
+	}, name)
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	if len(base) < 3 {
+		base = "user" + base
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		var count int64
+		// 已删除用户的用户名仍受唯一索引约束
+		if err := db.Unscoped().Model(&entity.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		suffix, err := utils.RandomString(3)
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s_%s", base, suffix)
+	}
+	return "", errors.New("生成用户名失败，请重试")
+}
@@ -0,0 +1,409 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog/internal/config"
+	"blog/internal/global"
+	"blog/internal/utils"
+	"blog/model/entity"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的scope
+const (
+	scopeOpenID  = "openid"
+	scopeProfile = "profile"
+	scopeEmail   = "email"
+)
+
+// OIDCError OAuth2/OIDC协议错误，按规范以error和error_description返回给客户端
+type OIDCError struct {
+	Code        string // invalid_request、invalid_client、invalid_grant等
+	Description string
+}
+
+func (e *OIDCError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func oidcError(code, description string) *OIDCError {
+	return &OIDCError{Code: code, Description: description}
+}
+
+// consumeHashScript 原子地读取并删除hash，保证授权码只能使用一次
+var consumeHashScript = redis.NewScript(`
+local data = redis.call("HGETALL", KEYS[1])
+redis.call("DEL", KEYS[1])
+return data
+`)
+
+// oidcCodeKey 授权码仅以哈希形式保存
+func oidcCodeKey(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return "oidc_code:" + hex.EncodeToString(sum[:])
+}
+
+// oidcAccessTokenKey OIDC access token仅以哈希形式保存，只能用于访问userinfo接口
+func oidcAccessTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "oidc_access_token:" + hex.EncodeToString(sum[:])
+}
+
+type OIDCService struct {
+	userService *UserService
+}
+
+func NewOIDCService(userService *UserService) *OIDCService {
+	return &OIDCService{
+		userService: userService,
+	}
+}
+
+// issuer 对外地址，不带末尾的斜杠
+func (s *OIDCService) issuer() string {
+	return strings.TrimRight(global.Config.OIDC.Issuer, "/")
+}
+
+// tokenTTL ID token和access token有效期
+func (s *OIDCService) tokenTTL() time.Duration {
+	return time.Duration(global.Config.OIDC.IDTokenExpireMinute) * time.Minute
+}
+
+// Discovery 发现文档
+func (s *OIDCService) Discovery() *resp.OIDCDiscovery {
+	issuer := s.issuer()
+	return &resp.OIDCDiscovery{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth2/authorize",
+		TokenEndpoint:                     issuer + "/oauth2/token",
+		UserinfoEndpoint:                  issuer + "/oauth2/userinfo",
+		JWKSURI:                           issuer + "/oauth2/jwks",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  s.signingAlgorithms(),
+		ScopesSupported:                   []string{scopeOpenID, scopeProfile, scopeEmail},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce",
+			"name", "preferred_username", "picture", "updated_at", "email", "email_verified",
+		},
+	}
+}
+
+// FindClient 查找客户端并校验回调地址，校验失败时不能重定向回客户端
+func (s *OIDCService) FindClient(clientID, redirectURI string) (*config.OIDCClientConfig, error) {
+	for i := range global.Config.OIDC.Clients {
+		client := &global.Config.OIDC.Clients[i]
+		if client.ClientID != clientID {
+			continue
+		}
+		for _, uri := range client.RedirectURIs {
+			if uri == redirectURI {
+				return client, nil
+			}
+		}
+		return nil, oidcError("invalid_request", "redirect_uri is not registered for this client")
+	}
+	return nil, oidcError("invalid_client", "unknown client_id")
+}
+
+// ValidateAuthorizeRequest 校验授权请求参数，返回的错误可以重定向回客户端
+func (s *OIDCService) ValidateAuthorizeRequest(client *config.OIDCClientConfig, req *req.OIDCAuthorizeRequest) error {
+	if req.ResponseType != "code" {
+		return oidcError("unsupported_response_type", "only response_type=code is supported")
+	}
+	scopes := strings.Fields(req.Scope)
+	if !containsString(scopes, scopeOpenID) {
+		return oidcError("invalid_scope", "scope must include openid")
+	}
+	for _, scope := range scopes {
+		if scope != scopeOpenID && scope != scopeProfile && scope != scopeEmail {
+			return oidcError("invalid_scope", "unsupported scope: "+scope)
+		}
+	}
+	if req.CodeChallenge != "" && req.CodeChallengeMethod != "S256" {
+		return oidcError("invalid_request", "only code_challenge_method=S256 is supported")
+	}
+	// 公开客户端无法保管密钥，必须使用PKCE
+	if client.ClientSecret == "" && req.CodeChallenge == "" {
+		return oidcError("invalid_request", "code_challenge is required for public clients")
+	}
+	return nil
+}
+
+// AuthorizeRedirectURL 生成携带参数重定向回客户端的地址
+func (s *OIDCService) AuthorizeRedirectURL(redirectURI string, params url.Values) string {
+	sep := "?"
+	if strings.Contains(redirectURI, "?") {
+		sep = "&"
+	}
+	return redirectURI + sep + params.Encode()
+}
+
+// Authorize 已登录用户同意授权，签发授权码并返回重定向回客户端的地址
+func (s *OIDCService) Authorize(ctx context.Context, userID uint, sessionID string, req *req.OIDCAuthorizeRequest) (*resp.OIDCAuthorizeResponse, error) {
+	client, err := s.FindClient(req.ClientID, req.RedirectURI)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ValidateAuthorizeRequest(client, req); err != nil {
+		return nil, err
+	}
+
+	// 认证时间取登录会话的创建时间
+	authTime := time.Now().Unix()
+	if sessionID != "" {
+		if createdAt, err := global.Redis.HGet(ctx, sessionKey(sessionID), "created_at").Int64(); err == nil {
+			authTime = createdAt
+		}
+	}
+
+	code, err := utils.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	key := oidcCodeKey(code)
+	_, err = global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"client_id", client.ClientID,
+			"redirect_uri", req.RedirectURI,
+			"user_id", userID,
+			"scope", strings.Join(strings.Fields(req.Scope), " "),
+			"nonce", req.Nonce,
+			"code_challenge", req.CodeChallenge,
+			"auth_time", authTime,
+		)
+		pipe.Expire(ctx, key, time.Duration(global.Config.OIDC.CodeTTL)*time.Second)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return &resp.OIDCAuthorizeResponse{
+		RedirectURL: s.AuthorizeRedirectURL(req.RedirectURI, params),
+	}, nil
+}
+
+// Token 使用授权码换取ID token和access token
+func (s *OIDCService) Token(ctx context.Context, req *req.OIDCTokenRequest) (*resp.OIDCTokenResponse, error) {
+	if req.GrantType != "authorization_code" {
+		return nil, oidcError("unsupported_grant_type", "only authorization_code is supported")
+	}
+	if req.Code == "" {
+		return nil, oidcError("invalid_request", "code is required")
+	}
+
+	// 校验客户端凭证
+	client, err := s.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := consumeHashScript.Run(ctx, global.Redis, []string{oidcCodeKey(req.Code)}).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]string, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		data[values[i]] = values[i+1]
+	}
+	if len(data) == 0 || data["client_id"] != client.ClientID {
+		return nil, oidcError("invalid_grant", "authorization code is invalid or expired")
+	}
+	if data["redirect_uri"] != req.RedirectURI {
+		return nil, oidcError("invalid_grant", "redirect_uri does not match")
+	}
+	if challenge := data["code_challenge"]; challenge != "" {
+		sum := sha256.Sum256([]byte(req.CodeVerifier))
+		expected := base64.RawURLEncoding.EncodeToString(sum[:])
+		if req.CodeVerifier == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) != 1 {
+			return nil, oidcError("invalid_grant", "code_verifier is invalid")
+		}
+	}
+
+	userID, _ := strconv.ParseUint(data["user_id"], 10, 32)
+	user, err := s.userService.GetUserByID(ctx, uint(userID))
+	if err != nil {
+		return nil, oidcError("invalid_grant", "user not found")
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, oidcError("invalid_grant", "user is not active")
+	}
+
+	scope := data["scope"]
+	ttl := s.tokenTTL()
+	accessToken, err := utils.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	accessKey := oidcAccessTokenKey(accessToken)
+	_, err = global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, accessKey, "user_id", user.ID, "client_id", client.ClientID, "scope", scope)
+		pipe.Expire(ctx, accessKey, ttl)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	authTime, _ := strconv.ParseInt(data["auth_time"], 10, 64)
+	claims := jwt.MapClaims{
+		"iss":       s.issuer(),
+		"sub":       strconv.FormatUint(uint64(user.ID), 10),
+		"aud":       client.ClientID,
+		"iat":       now.Unix(),
+		"exp":       now.Add(ttl).Unix(),
+		"auth_time": authTime,
+	}
+	if nonce := data["nonce"]; nonce != "" {
+		claims["nonce"] = nonce
+	}
+	for k, v := range s.userClaims(user, strings.Fields(scope)) {
+		claims[k] = v
+	}
+	// at_hash的哈希算法取决于签名密钥，先确定密钥再计算
+	key, err := utils.GetSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	claims["at_hash"] = s.tokenHash(key.Algorithm, accessToken)
+	idToken, err := key.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.OIDCTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(ttl.Seconds()),
+		IDToken:     idToken,
+		Scope:       scope,
+	}, nil
+}
+
+// UserInfo 根据OIDC access token返回授权范围内的用户信息
+func (s *OIDCService) UserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	data, err := global.Redis.HGetAll(ctx, oidcAccessTokenKey(accessToken)).Result()
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseUint(data["user_id"], 10, 32)
+	if userID == 0 {
+		return nil, oidcError("invalid_token", "access token is invalid or expired")
+	}
+
+	user, err := s.userService.GetUserByID(ctx, uint(userID))
+	if err != nil {
+		return nil, oidcError("invalid_token", "user not found")
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, oidcError("invalid_token", "user is not active")
+	}
+
+	claims := s.userClaims(user, strings.Fields(data["scope"]))
+	claims["sub"] = strconv.FormatUint(uint64(user.ID), 10)
+	return claims, nil
+}
+
+// authenticateClient 校验客户端凭证，公开客户端只需提供client_id
+func (s *OIDCService) authenticateClient(clientID, clientSecret string) (*config.OIDCClientConfig, error) {
+	for i := range global.Config.OIDC.Clients {
+		client := &global.Config.OIDC.Clients[i]
+		if client.ClientID != clientID {
+			continue
+		}
+		if client.ClientSecret != "" &&
+			subtle.ConstantTimeCompare([]byte(client.ClientSecret), []byte(clientSecret)) != 1 {
+			break
+		}
+		return client, nil
+	}
+	return nil, oidcError("invalid_client", "client authentication failed")
+}
+
+// userClaims 按scope返回用户信息声明
+func (s *OIDCService) userClaims(user *entity.User, scopes []string) map[string]interface{} {
+	claims := make(map[string]interface{})
+	if containsString(scopes, scopeProfile) {
+		claims["name"] = user.Nickname
+		claims["preferred_username"] = user.Username
+		claims["updated_at"] = user.UpdatedAt.Unix()
+		if user.Avatar != "" {
+			claims["picture"] = user.Avatar
+		}
+	}
+	if containsString(scopes, scopeEmail) {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.EmailVerifiedAt != nil
+	}
+	return claims
+}
+
+// tokenHash 计算ID token中的at_hash：按签名算法对应的哈希取左半部分
+func (s *OIDCService) tokenHash(alg, token string) string {
+	var sum []byte
+	if alg == utils.AlgEdDSA {
+		h := sha512.Sum512([]byte(token))
+		sum = h[:]
+	} else {
+		h := sha256.Sum256([]byte(token))
+		sum = h[:]
+	}
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
+
+// signingAlgorithms 当前发布的验证密钥使用的签名算法
+func (s *OIDCService) signingAlgorithms() []string {
+	algs := []string{}
+	jwks, err := utils.GetJWKS()
+	if err != nil {
+		return algs
+	}
+	for _, key := range jwks.Keys {
+		if !containsString(algs, key.Alg) {
+			algs = append(algs, key.Alg)
+		}
+	}
+	return algs
+}
+
+// containsString 判断切片是否包含指定字符串
+func containsString(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOIDCError 判断是否为协议错误
+func IsOIDCError(err error) (*OIDCError, bool) {
+	var oidcErr *OIDCError
+	ok := errors.As(err, &oidcErr)
+	return oidcErr, ok
+}
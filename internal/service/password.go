@@ -0,0 +1,50 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+
+	"blog/internal/global"
+)
+
+// maxPasswordBytes bcrypt只使用密码的前72个字节
+const maxPasswordBytes = 72
+
+// checkPasswordPolicy 按配置的密码策略校验密码强度
+func checkPasswordPolicy(password string) error {
+	policy := global.Config.Password
+
+	if len([]rune(password)) < policy.MinLength {
+		return fmt.Errorf("密码长度不能少于%d位", policy.MinLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("密码长度不能超过%d个字节", maxPasswordBytes)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	switch {
+	case policy.RequireUpper && !upper:
+		return errors.New("密码必须包含大写字母")
+	case policy.RequireLower && !lower:
+		return errors.New("密码必须包含小写字母")
+	case policy.RequireDigit && !digit:
+		return errors.New("密码必须包含数字")
+	case policy.RequireSymbol && !symbol:
+		return errors.New("密码必须包含特殊字符")
+	}
+	return nil
+}
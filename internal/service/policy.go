@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"blog/internal/rbac"
+	"blog/model/entity"
+
+	"gorm.io/gorm"
+)
+
+// authorize 校验用户能否操作资源：资源所有者直接放行，否则按用户角色判断是否拥有对应管理权限
+// 使用访问令牌时，令牌的授权范围也必须包含该管理权限
+func authorize(ctx context.Context, db *gorm.DB, userID, ownerID uint, perm rbac.Permission) (bool, error) {
+	if userID == ownerID {
+		return true, nil
+	}
+	if userID == 0 || !rbac.ScopeAllows(ctx, perm) {
+		return false, nil
+	}
+
+	var user entity.User
+	if err := db.Select("id", "role").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return rbac.CanManage(userID, user.Role, ownerID, perm), nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"blog/internal/global"
+	"blog/internal/markdown"
+	"blog/model/entity"
+)
+
+// renderCacheTTL 渲染结果缓存时间
+// 缓存key由渲染规则版本和正文哈希组成，正文修改后自然失效
+const renderCacheTTL = 24 * time.Hour
+
+// RenderArticles 将文章正文渲染为HTML，填充content_html、toc和reading_minutes，并清空原始正文
+func (s *ArticleService) RenderArticles(ctx context.Context, articles ...*entity.Article) error {
+	for _, article := range articles {
+		result, err := renderContent(ctx, article.Content)
+		if err != nil {
+			return err
+		}
+		article.ContentHTML = result.HTML
+		article.TOC = toTOCItems(result.TOC)
+		article.ReadingMinutes = result.ReadingMinutes
+		article.Content = ""
+	}
+	return nil
+}
+
+// renderContent 渲染Markdown，优先读取Redis缓存，缓存不可用时直接渲染
+func renderContent(ctx context.Context, content string) (*markdown.Result, error) {
+	sum := sha1.Sum([]byte(content))
+	key := fmt.Sprintf("article_render:v%d:%s", markdown.Version, hex.EncodeToString(sum[:]))
+
+	if data, err := global.Redis.Get(ctx, key).Bytes(); err == nil {
+		var result markdown.Result
+		if err := json.Unmarshal(data, &result); err == nil {
+			return &result, nil
+		}
+	}
+
+	result, err := markdown.Render(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(result); err == nil {
+		if err := global.Redis.Set(ctx, key, data, renderCacheTTL).Err(); err != nil {
+			log.Printf("Failed to cache rendered content: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// toTOCItems 转换目录结构
+func toTOCItems(headings []*markdown.Heading) []*entity.TOCItem {
+	items := make([]*entity.TOCItem, 0, len(headings))
+	for _, h := range headings {
+		items = append(items, &entity.TOCItem{
+			Level:    h.Level,
+			ID:       h.ID,
+			Text:     h.Text,
+			Children: toTOCItems(h.Children),
+		})
+	}
+	return items
+}
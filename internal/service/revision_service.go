@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"blog/internal/global"
+	"blog/internal/rbac"
+	"blog/internal/utils"
+	"blog/model/entity"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"gorm.io/gorm"
+)
+
+type RevisionService struct {
+	articleService *ArticleService
+}
+
+func NewRevisionService(articleService *ArticleService) *RevisionService {
+	return &RevisionService{
+		articleService: articleService,
+	}
+}
+
+// getDB 获取数据库连接，支持事务
+func (s *RevisionService) getDB(ctx context.Context) *gorm.DB {
+	return global.GetDB(ctx)
+}
+
+// GetRevisions 获取文章修订历史，不包含正文
+func (s *RevisionService) GetRevisions(ctx context.Context, articleID, userID uint) ([]*entity.ArticleRevision, error) {
+	db := s.getDB(ctx)
+
+	if _, err := s.checkArticleAuthor(ctx, articleID, userID); err != nil {
+		return nil, err
+	}
+
+	var revisions []*entity.ArticleRevision
+	if err := db.Omit("content").
+		Where("article_id = ?", articleID).
+		Order("version DESC").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetRevision 获取指定版本的修订内容
+func (s *RevisionService) GetRevision(ctx context.Context, articleID uint, version int, userID uint) (*entity.ArticleRevision, error) {
+	if _, err := s.checkArticleAuthor(ctx, articleID, userID); err != nil {
+		return nil, err
+	}
+	return s.getRevision(ctx, articleID, version)
+}
+
+// DiffRevisions 比较两个版本的行级差异
+func (s *RevisionService) DiffRevisions(ctx context.Context, articleID, userID uint, req *req.RevisionDiffRequest) (*resp.RevisionDiffResponse, error) {
+	if _, err := s.checkArticleAuthor(ctx, articleID, userID); err != nil {
+		return nil, err
+	}
+
+	from, err := s.getRevision(ctx, articleID, req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.getRevision(ctx, articleID, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.RevisionDiffResponse{
+		ArticleID: articleID,
+		From:      from.Version,
+		To:        to.Version,
+		FromTitle: from.Title,
+		ToTitle:   to.Title,
+		Lines:     utils.DiffLines(from.Content, to.Content),
+	}, nil
+}
+
+// RestoreRevision 将文章恢复到指定版本，恢复操作本身会产生一个新版本
+func (s *RevisionService) RestoreRevision(ctx context.Context, articleID uint, version int, userID uint) (*entity.Article, error) {
+	article, err := s.checkArticleAuthor(ctx, articleID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.getRevision(ctx, articleID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// 仅恢复标题、正文和摘要，其余属性保持不变
+	update := &req.ArticleUpdateRequest{
+		Title:      revision.Title,
+		Content:    revision.Content,
+		Summary:    revision.Summary,
+		CoverImage: article.CoverImage,
+		Status:     article.Status,
+		PublishAt:  article.PublishedAt,
+		Tags:       article.Tags,
+	}
+	if article.CategoryID != nil {
+		update.CategoryID = *article.CategoryID
+	}
+
+	return s.articleService.UpdateArticle(ctx, articleID, userID, update)
+}
+
+// checkArticleAuthor 校验文章存在且当前用户为作者或文章管理员
+func (s *RevisionService) checkArticleAuthor(ctx context.Context, articleID, userID uint) (*entity.Article, error) {
+	db := s.getDB(ctx)
+
+	var article entity.Article
+	if err := db.First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("文章不存在")
+		}
+		return nil, err
+	}
+
+	allowed, err := authorize(ctx, db, userID, article.AuthorID, rbac.PermArticleModerate)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("无权限查看此文章的修订历史")
+	}
+	return &article, nil
+}
+
+// getRevision 获取指定版本的修订
+func (s *RevisionService) getRevision(ctx context.Context, articleID uint, version int) (*entity.ArticleRevision, error) {
+	db := s.getDB(ctx)
+
+	var revision entity.ArticleRevision
+	if err := db.Where("article_id = ? AND version = ?", articleID, version).First(&revision).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("修订版本不存在")
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// saveRevision 记录文章当前内容为一个新版本
+func saveRevision(db *gorm.DB, article *entity.Article, editorID uint) error {
+	var latest int
+	if err := db.Model(&entity.ArticleRevision{}).
+		Where("article_id = ?", article.ID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error; err != nil {
+		return err
+	}
+
+	return db.Create(&entity.ArticleRevision{
+		ArticleID: article.ID,
+		Version:   latest + 1,
+		Title:     article.Title,
+		Content:   article.Content,
+		Summary:   article.Summary,
+		EditorID:  editorID,
+	}).Error
+}
+
+// hasRevisions 判断文章是否已有修订记录
+func hasRevisions(db *gorm.DB, articleID uint) (bool, error) {
+	var count int64
+	err := db.Model(&entity.ArticleRevision{}).Where("article_id = ?", articleID).Count(&count).Error
+	return count > 0, err
+}
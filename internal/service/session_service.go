@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"blog/internal/global"
+	"blog/model/resp"
+)
+
+type SessionService struct{}
+
+func NewSessionService() *SessionService {
+	return &SessionService{}
+}
+
+// GetSessions 获取用户的所有登录会话，按最近活跃时间倒序
+func (s *SessionService) GetSessions(ctx context.Context, userID uint, currentSessionID string) ([]*resp.SessionResponse, error) {
+	familiesKey := userTokenFamiliesKey(userID)
+	families, err := global.Redis.SMembers(ctx, familiesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*resp.SessionResponse, 0, len(families))
+	for _, family := range families {
+		data, err := global.Redis.HGetAll(ctx, sessionKey(family)).Result()
+		if err != nil {
+			return nil, err
+		}
+		// 会话已过期，清理残留的令牌族记录
+		if len(data) == 0 {
+			global.Redis.SRem(ctx, familiesKey, family)
+			continue
+		}
+
+		createdAt, _ := strconv.ParseInt(data["created_at"], 10, 64)
+		lastSeenAt, _ := strconv.ParseInt(data["last_seen_at"], 10, 64)
+		sessions = append(sessions, &resp.SessionResponse{
+			ID:         family,
+			IP:         data["ip"],
+			UserAgent:  data["user_agent"],
+			CreatedAt:  time.Unix(createdAt, 0),
+			LastSeenAt: time.Unix(lastSeenAt, 0),
+			Current:    family == currentSessionID,
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession 注销指定会话，该会话的access token和refresh token立即失效
+func (s *SessionService) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	owned, err := global.Redis.SIsMember(ctx, userTokenFamiliesKey(userID), sessionID).Result()
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return errors.New("会话不存在")
+	}
+	return revokeTokenFamily(ctx, userID, sessionID)
+}
+
+// RevokeOtherSessions 注销除当前会话外的所有会话，返回注销的会话数量
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, userID uint, currentSessionID string) (int, error) {
+	families, err := global.Redis.SMembers(ctx, userTokenFamiliesKey(userID)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, family := range families {
+		if family == currentSessionID {
+			continue
+		}
+		if err := revokeTokenFamily(ctx, userID, family); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
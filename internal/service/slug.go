@@ -0,0 +1,87 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"blog/internal/utils"
+	"blog/model/entity"
+
+	"gorm.io/gorm"
+)
+
+// maxSlugAttempts 自动生成slug时解决冲突的最大尝试次数
+const maxSlugAttempts = 100
+
+// assignSlug 设置文章slug
+// explicit非空时使用作者指定的slug，被其他文章占用则报错；否则根据标题生成，冲突时追加数字后缀
+// 旧slug保留在article_slugs表中，用于跳转
+func assignSlug(db *gorm.DB, article *entity.Article, explicit string) error {
+	var slug string
+	if explicit != "" {
+		if !utils.IsValidSlug(explicit) {
+			return errors.New("slug只能包含小写字母、数字和短横线")
+		}
+		owner, err := slugOwner(db, explicit)
+		if err != nil {
+			return err
+		}
+		if owner != 0 && owner != article.ID {
+			return errors.New("slug已被其他文章占用")
+		}
+		slug = explicit
+	} else {
+		base := utils.Slugify(article.Title)
+		if base == "" {
+			base = "article"
+		}
+		for i := 1; ; i++ {
+			if i > maxSlugAttempts {
+				// 极端情况下使用文章ID保证唯一
+				slug = fmt.Sprintf("%s-%d", base, article.ID)
+				break
+			}
+			candidate := base
+			if i > 1 {
+				candidate = fmt.Sprintf("%s-%d", base, i)
+			}
+			owner, err := slugOwner(db, candidate)
+			if err != nil {
+				return err
+			}
+			if owner == 0 || owner == article.ID {
+				slug = candidate
+				break
+			}
+		}
+	}
+
+	if slug == article.Slug {
+		return nil
+	}
+
+	owner, err := slugOwner(db, slug)
+	if err != nil {
+		return err
+	}
+	if owner == 0 {
+		if err := db.Create(&entity.ArticleSlug{ArticleID: article.ID, Slug: slug}).Error; err != nil {
+			return err
+		}
+	}
+
+	article.Slug = slug
+	return db.Model(article).UpdateColumn("slug", slug).Error
+}
+
+// slugOwner 获取占用slug的文章ID，未被占用时返回0
+func slugOwner(db *gorm.DB, slug string) (uint, error) {
+	var record entity.ArticleSlug
+	if err := db.Where("slug = ?", slug).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return record.ArticleID, nil
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"blog/internal/global"
+	"blog/model/entity"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"gorm.io/gorm"
+)
+
+type TagService struct{}
+
+func NewTagService() *TagService {
+	return &TagService{}
+}
+
+// getDB 获取数据库连接，支持事务
+func (s *TagService) getDB(ctx context.Context) *gorm.DB {
+	return global.GetDB(ctx)
+}
+
+// GetTags 获取所有标签及其已发布文章数
+func (s *TagService) GetTags(ctx context.Context) ([]*resp.TagResponse, error) {
+	db := s.getDB(ctx)
+
+	var tags []*resp.TagResponse
+	err := db.Table("tags").
+		Select("tags.id, tags.name, COUNT(articles.id) AS article_count").
+		Joins("LEFT JOIN article_tags ON article_tags.tag_id = tags.id").
+		Joins("LEFT JOIN articles ON articles.id = article_tags.article_id AND articles.status = 1 AND articles.deleted_at IS NULL").
+		Group("tags.id, tags.name").
+		Order("article_count DESC, tags.id ASC").
+		Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// CreateTag 创建标签
+func (s *TagService) CreateTag(ctx context.Context, req *req.TagCreateRequest) (*entity.Tag, error) {
+	db := s.getDB(ctx)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("标签名称不能为空")
+	}
+
+	var count int64
+	if err := db.Model(&entity.Tag{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("标签已存在")
+	}
+
+	tag := &entity.Tag{Name: name}
+	if err := db.Create(tag).Error; err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// RenameTag 重命名标签，并同步文章的标签字段
+func (s *TagService) RenameTag(ctx context.Context, id uint, req *req.TagUpdateRequest) (*entity.Tag, error) {
+	db := s.getDB(ctx)
+
+	tag, err := s.getTag(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("标签名称不能为空")
+	}
+
+	var count int64
+	if err := db.Model(&entity.Tag{}).Where("name = ? AND id <> ?", name, id).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("标签已存在，请使用合并功能")
+	}
+
+	if err := db.Model(tag).Update("name", name).Error; err != nil {
+		return nil, err
+	}
+
+	articleIDs, err := taggedArticleIDs(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := syncArticleTagStrings(db, articleIDs); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// MergeTag 将标签合并到目标标签，原标签下的文章改为关联目标标签，原标签删除
+func (s *TagService) MergeTag(ctx context.Context, id uint, req *req.TagMergeRequest) (*entity.Tag, error) {
+	db := s.getDB(ctx)
+
+	if id == req.TargetID {
+		return nil, errors.New("不能合并到自身")
+	}
+
+	source, err := s.getTag(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.getTag(ctx, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	articleIDs, err := taggedArticleIDs(db, source.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 已关联目标标签的文章只需删除原关联，其余改为关联目标标签
+	if len(articleIDs) > 0 {
+		err := db.Exec(
+			"UPDATE article_tags SET tag_id = ? WHERE tag_id = ? AND article_id NOT IN (SELECT article_id FROM (SELECT article_id FROM article_tags WHERE tag_id = ?) AS t)",
+			target.ID, source.ID, target.ID,
+		).Error
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Exec("DELETE FROM article_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Delete(source).Error; err != nil {
+		return nil, err
+	}
+
+	if err := syncArticleTagStrings(db, articleIDs); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// MigrateLegacyTags 将旧版逗号分隔的标签字段解析到标签表
+// 仅处理尚未建立标签关联的文章，可重复执行
+func (s *TagService) MigrateLegacyTags(ctx context.Context) error {
+	db := s.getDB(ctx)
+
+	var articles []*entity.Article
+	err := db.Select("id", "tags").
+		Where("tags <> ''").
+		Where("id NOT IN (?)", db.Table("article_tags").Select("article_id")).
+		Find(&articles).Error
+	if err != nil {
+		return err
+	}
+
+	migrated := 0
+	for _, article := range articles {
+		// 旧数据未校验标签长度，超长的标签截断后再迁移
+		names := splitTagNames(article.Tags)
+		for i, name := range names {
+			if runes := []rune(name); len(runes) > maxTagNameLen {
+				names[i] = string(runes[:maxTagNameLen])
+				log.Printf("Warning: truncated legacy tag %q of article %d", name, article.ID)
+			}
+		}
+
+		// 单篇文章迁移失败不影响启动，下次启动时重试
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return setArticleTags(tx, article, strings.Join(names, ","))
+		})
+		if err != nil {
+			log.Printf("Warning: failed to migrate legacy tags of article %d: %v", article.ID, err)
+			continue
+		}
+		migrated++
+	}
+
+	if migrated > 0 {
+		log.Printf("Migrated legacy tags for %d articles", migrated)
+	}
+	return nil
+}
+
+// getTag 根据ID获取标签
+func (s *TagService) getTag(ctx context.Context, id uint) (*entity.Tag, error) {
+	db := s.getDB(ctx)
+
+	var tag entity.Tag
+	if err := db.First(&tag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("标签不存在")
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// maxTagNameLen 标签名称最大长度(字符)，与标签表name字段长度一致
+const maxTagNameLen = 50
+
+// parseTagNames 解析逗号分隔的标签字符串，去除空白与重复项，并校验每个标签的长度
+func parseTagNames(tags string) ([]string, error) {
+	names := splitTagNames(tags)
+	for _, name := range names {
+		if utf8.RuneCountInString(name) > maxTagNameLen {
+			return nil, fmt.Errorf("标签长度不能超过%d个字符: %s", maxTagNameLen, name)
+		}
+	}
+	return names, nil
+}
+
+// splitTagNames 拆分逗号分隔的标签字符串，去除空白与重复项
+func splitTagNames(tags string) []string {
+	fields := strings.FieldsFunc(tags, func(r rune) bool {
+		return r == ',' || r == '，'
+	})
+
+	seen := make(map[string]bool, len(fields))
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		name := strings.TrimSpace(field)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// setArticleTags 根据逗号分隔的标签字符串设置文章标签，不存在的标签自动创建
+func setArticleTags(db *gorm.DB, article *entity.Article, tagsStr string) error {
+	names, err := parseTagNames(tagsStr)
+	if err != nil {
+		return err
+	}
+
+	tags := make([]*entity.Tag, 0, len(names))
+	for _, name := range names {
+		tag := &entity.Tag{}
+		if err := db.Where(entity.Tag{Name: name}).FirstOrCreate(tag).Error; err != nil {
+			return err
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := db.Model(article).Association("TagList").Replace(tags); err != nil {
+		return err
+	}
+
+	article.Tags = strings.Join(names, ",")
+	return db.Model(article).UpdateColumn("tags", article.Tags).Error
+}
+
+// taggedArticleIDs 获取关联了指定标签的文章ID
+func taggedArticleIDs(db *gorm.DB, tagID uint) ([]uint, error) {
+	var ids []uint
+	err := db.Table("article_tags").Where("tag_id = ?", tagID).Pluck("article_id", &ids).Error
+	return ids, err
+}
+
+// syncArticleTagStrings 根据标签关联重新生成文章的标签字段
+func syncArticleTagStrings(db *gorm.DB, articleIDs []uint) error {
+	if len(articleIDs) == 0 {
+		return nil
+	}
+
+	var articles []*entity.Article
+	if err := db.Preload("TagList").Select("id").Where("id IN ?", articleIDs).Find(&articles).Error; err != nil {
+		return err
+	}
+
+	for _, article := range articles {
+		names := make([]string, 0, len(article.TagList))
+		for _, tag := range article.TagList {
+			names = append(names, tag.Name)
+		}
+		if err := db.Model(article).UpdateColumn("tags", strings.Join(names, ",")).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
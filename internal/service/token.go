@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog/internal/global"
+	"blog/internal/utils"
+	"blog/model/resp"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// refreshTokenBytes refresh token的随机字节数
+const refreshTokenBytes = 32
+
+// useRefreshScript 原子地标记refresh token已使用，返回使用次数，token不存在时返回-1
+var useRefreshScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -1
+end
+return redis.call("HINCRBY", KEYS[1], "used", 1)
+`)
+
+// touchSessionScript 会话存在时更新会话字段，ARGV[1]为新的过期时间(秒，0表示不修改)，其后为字段和值
+// 会话不存在时不做任何操作，避免为已注销的会话重新创建key
+var touchSessionScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+for i = 2, #ARGV, 2 do
+	redis.call("HSET", KEYS[1], ARGV[i], ARGV[i + 1])
+end
+if tonumber(ARGV[1]) > 0 then
+	redis.call("EXPIRE", KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// refreshTokenKey refresh token仅以哈希形式保存在Redis中
+func refreshTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "refresh_token:" + hex.EncodeToString(sum[:])
+}
+
+// tokenFamilyKey 令牌族包含的所有Redis key，一次登录及其后续刷新产生的令牌属于同一令牌族
+func tokenFamilyKey(family string) string {
+	return "token_family:" + family
+}
+
+// sessionKey 登录会话信息，每个令牌族对应一个会话
+func sessionKey(family string) string {
+	return "session:" + family
+}
+
+// userTokenFamiliesKey 用户的令牌族集合
+func userTokenFamiliesKey(userID uint) string {
+	return fmt.Sprintf("user_token_families:%d", userID)
+}
+
+// refreshExpireDuration refresh token有效期
+func refreshExpireDuration() time.Duration {
+	return time.Duration(global.Config.JWT.RefreshExpireHour) * time.Hour
+}
+
+// issueTokens 在指定令牌族下签发access token和refresh token
+func issueTokens(ctx context.Context, userID uint, family string) (*resp.TokenResponse, error) {
+	accessToken, err := utils.GenerateToken(userID, family)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := utils.RandomString(refreshTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	accessTTL := utils.GetJWTExpireDuration()
+	refreshTTL := refreshExpireDuration()
+	tokenKey := fmt.Sprintf("token:%s", accessToken)
+	userTokensKey := fmt.Sprintf("user_tokens:%d", userID)
+	refreshKey := refreshTokenKey(refreshToken)
+	familyKey := tokenFamilyKey(family)
+	familiesKey := userTokenFamiliesKey(userID)
+
+	_, err = global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// access token沿用token:{token}和user_tokens:{id}，支持多设备登录
+		pipe.Set(ctx, tokenKey, userID, accessTTL)
+		pipe.SAdd(ctx, userTokensKey, accessToken)
+		pipe.Expire(ctx, userTokensKey, accessTTL)
+
+		pipe.HSet(ctx, refreshKey, "user_id", userID, "family", family, "used", 0)
+		pipe.Expire(ctx, refreshKey, refreshTTL)
+
+		pipe.SAdd(ctx, familyKey, tokenKey, refreshKey)
+		pipe.Expire(ctx, familyKey, refreshTTL)
+		pipe.SAdd(ctx, familiesKey, family)
+		pipe.Expire(ctx, familiesKey, refreshTTL)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.TokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTTL.Seconds()),
+	}, nil
+}
+
+// newTokenFamily 为一次新的登录签发令牌，并记录登录设备信息
+func newTokenFamily(ctx context.Context, userID uint, ip, userAgent string) (*resp.TokenResponse, error) {
+	family, err := utils.RandomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	key := sessionKey(family)
+	_, err = global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"user_id", userID,
+			"ip", ip,
+			"user_agent", userAgent,
+			"created_at", now,
+			"last_seen_at", now,
+		)
+		pipe.Expire(ctx, key, refreshExpireDuration())
+		// 会话信息随令牌族一起注销
+		pipe.SAdd(ctx, tokenFamilyKey(family), key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return issueTokens(ctx, userID, family)
+}
+
+// touchSession 更新会话最近活跃时间，ip非空时同时更新IP，ttl大于0时延长会话有效期
+func touchSession(ctx context.Context, family, ip string, ttl time.Duration) error {
+	args := []interface{}{int64(ttl.Seconds()), "last_seen_at", time.Now().Unix()}
+	if ip != "" {
+		args = append(args, "ip", ip)
+	}
+	return touchSessionScript.Run(ctx, global.Redis, []string{sessionKey(family)}, args...).Err()
+}
+
+// useRefreshToken 校验并作废refresh token，返回其所属用户和令牌族
+// 已使用过的refresh token再次出现说明可能被盗用，此时注销整个令牌族
+func useRefreshToken(ctx context.Context, refreshToken string) (uint, string, error) {
+	key := refreshTokenKey(refreshToken)
+
+	data, err := global.Redis.HGetAll(ctx, key).Result()
+	if err != nil {
+		return 0, "", err
+	}
+	userID, _ := strconv.ParseUint(data["user_id"], 10, 32)
+	family := data["family"]
+	if userID == 0 || family == "" {
+		return 0, "", errors.New("refresh token无效或已过期")
+	}
+
+	used, err := useRefreshScript.Run(ctx, global.Redis, []string{key}).Int64()
+	if err != nil {
+		return 0, "", err
+	}
+	if used < 0 {
+		return 0, "", errors.New("refresh token无效或已过期")
+	}
+	if used > 1 {
+		log.Printf("Refresh token reuse detected for user %d, revoking token family %s", userID, family)
+		if err := revokeTokenFamily(ctx, uint(userID), family); err != nil {
+			return 0, "", err
+		}
+		return 0, "", errors.New("refresh token已被使用，请重新登录")
+	}
+
+	return uint(userID), family, nil
+}
+
+// revokeTokenFamily 注销令牌族下的所有access token和refresh token
+func revokeTokenFamily(ctx context.Context, userID uint, family string) error {
+	familyKey := tokenFamilyKey(family)
+	keys, err := global.Redis.SMembers(ctx, familyKey).Result()
+	if err != nil {
+		return err
+	}
+
+	userTokensKey := fmt.Sprintf("user_tokens:%d", userID)
+	_, err = global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			if token, ok := strings.CutPrefix(key, "token:"); ok {
+				pipe.SRem(ctx, userTokensKey, token)
+			}
+			pipe.Del(ctx, key)
+		}
+		pipe.Del(ctx, familyKey)
+		pipe.SRem(ctx, userTokenFamiliesKey(userID), family)
+		return nil
+	})
+	return err
+}
+
+// revokeAllTokenFamilies 注销用户的所有令牌族
+func revokeAllTokenFamilies(ctx context.Context, userID uint) error {
+	families, err := global.Redis.SMembers(ctx, userTokenFamiliesKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	for _, family := range families {
+		if err := revokeTokenFamily(ctx, userID, family); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeOtherTokens 注销用户除当前会话外的所有token
+// 当前会话的token保留；旧版不属于任何令牌族的token中仅保留currentToken
+func revokeOtherTokens(ctx context.Context, userID uint, currentSessionID, currentToken string) error {
+	families, err := global.Redis.SMembers(ctx, userTokenFamiliesKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	for _, family := range families {
+		if family == currentSessionID {
+			continue
+		}
+		if err := revokeTokenFamily(ctx, userID, family); err != nil {
+			return err
+		}
+	}
+
+	keep := map[string]bool{"token:" + currentToken: true}
+	if currentSessionID != "" {
+		keys, err := global.Redis.SMembers(ctx, tokenFamilyKey(currentSessionID)).Result()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			keep[key] = true
+		}
+	}
+
+	// 清理user_tokens中剩余的其他token
+	userTokensKey := fmt.Sprintf("user_tokens:%d", userID)
+	tokens, err := global.Redis.SMembers(ctx, userTokensKey).Result()
+	if err != nil {
+		return err
+	}
+	_, err = global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, token := range tokens {
+			tokenKey := fmt.Sprintf("token:%s", token)
+			if keep[tokenKey] {
+				continue
+			}
+			pipe.Del(ctx, tokenKey)
+			pipe.SRem(ctx, userTokensKey, token)
+		}
+		return nil
+	})
+	return err
+}
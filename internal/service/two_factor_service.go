@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog/internal/global"
+	"blog/internal/utils"
+	"blog/model/entity"
+	"blog/model/req"
+	"blog/model/resp"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// totpEnrollTTL 待确认的TOTP密钥有效期
+	totpEnrollTTL = 10 * time.Minute
+	// totpUsedTTL 已使用验证码的记录时间，覆盖验证码允许的时间偏差范围
+	totpUsedTTL = 90 * time.Second
+	// loginChallengeTTL 两步验证登录挑战的有效期
+	loginChallengeTTL = 5 * time.Minute
+	// loginChallengeMaxAttempts 每个登录挑战允许的最大验证次数
+	loginChallengeMaxAttempts = 5
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+)
+
+// attemptLoginChallengeScript 增加挑战的验证次数，返回验证次数，挑战不存在时返回-1
+var attemptLoginChallengeScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -1
+end
+return redis.call("HINCRBY", KEYS[1], "attempts", 1)
+`)
+
+// totpEnrollKey 用户待确认的TOTP密钥
+func totpEnrollKey(userID uint) string {
+	return fmt.Sprintf("totp_enroll:%d", userID)
+}
+
+// totpUsedKey 已使用过的验证码时间步，防止验证码在有效期内被重放
+func totpUsedKey(userID uint, step int64) string {
+	return fmt.Sprintf("totp_used:%d:%d", userID, step)
+}
+
+// loginChallengeKey 登录挑战令牌仅以哈希形式保存在Redis中
+func loginChallengeKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "login_challenge:" + hex.EncodeToString(sum[:])
+}
+
+// hashRecoveryCode 恢复码忽略大小写和分隔符后取哈希
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// newLoginChallenge 密码验证通过后创建两步验证登录挑战，返回挑战令牌
+func newLoginChallenge(ctx context.Context, userID uint, ip, userAgent string) (string, error) {
+	token, err := utils.RandomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	key := loginChallengeKey(token)
+	_, err = global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "user_id", userID, "ip", ip, "user_agent", userAgent, "attempts", 0)
+		pipe.Expire(ctx, key, loginChallengeTTL)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+type TwoFactorService struct{}
+
+func NewTwoFactorService() *TwoFactorService {
+	return &TwoFactorService{}
+}
+
+// getDB 获取数据库连接，支持事务
+func (s *TwoFactorService) getDB(ctx context.Context) *gorm.DB {
+	return global.GetDB(ctx)
+}
+
+// Setup 生成新的TOTP密钥，需使用验证码确认后才会开启两步验证
+func (s *TwoFactorService) Setup(ctx context.Context, userID uint) (*resp.TwoFactorSetupResponse, error) {
+	var user entity.User
+	if err := s.getDB(ctx).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("两步验证已开启")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := global.Redis.Set(ctx, totpEnrollKey(userID), secret, totpEnrollTTL).Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(global.Config.Site.Title, user.Username, secret),
+	}, nil
+}
+
+// Confirm 校验验证码后开启两步验证，返回恢复码
+func (s *TwoFactorService) Confirm(ctx context.Context, userID uint, req *req.TwoFactorCodeRequest) ([]string, error) {
+	db := s.getDB(ctx)
+
+	var user entity.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("两步验证已开启")
+	}
+
+	secret, err := global.Redis.Get(ctx, totpEnrollKey(userID)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.New("请先获取两步验证密钥")
+		}
+		return nil, err
+	}
+	if err := useTOTPCode(ctx, userID, secret, req.Code); err != nil {
+		return nil, err
+	}
+
+	if err := db.Model(&user).Updates(map[string]interface{}{
+		"totp_secret":  secret,
+		"totp_enabled": true,
+	}).Error; err != nil {
+		return nil, err
+	}
+	codes, err := s.resetRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	global.Redis.Del(ctx, totpEnrollKey(userID))
+	return codes, nil
+}
+
+// Disable 校验密码和验证码(或恢复码)后关闭两步验证
+func (s *TwoFactorService) Disable(ctx context.Context, userID uint, req *req.TwoFactorDisableRequest) error {
+	db := s.getDB(ctx)
+
+	var user entity.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return errors.New("两步验证未开启")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return errors.New("密码错误")
+	}
+	if err := s.verifyCode(ctx, &user, req.Code); err != nil {
+		return err
+	}
+
+	if err := db.Model(&user).Updates(map[string]interface{}{
+		"totp_secret":  "",
+		"totp_enabled": false,
+	}).Error; err != nil {
+		return err
+	}
+	return db.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error
+}
+
+// RegenerateRecoveryCodes 校验验证码后重新生成恢复码，旧恢复码全部作废
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uint, req *req.TwoFactorCodeRequest) ([]string, error) {
+	var user entity.User
+	if err := s.getDB(ctx).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, errors.New("两步验证未开启")
+	}
+	if err := useTOTPCode(ctx, userID, user.TOTPSecret, req.Code); err != nil {
+		return nil, err
+	}
+	return s.resetRecoveryCodes(ctx, userID)
+}
+
+// Login 使用登录挑战和验证码(或恢复码)完成两步验证登录
+func (s *TwoFactorService) Login(ctx context.Context, req *req.TwoFactorLoginRequest) (*resp.UserLoginResponse, error) {
+	key := loginChallengeKey(req.ChallengeToken)
+	attempts, err := attemptLoginChallengeScript.Run(ctx, global.Redis, []string{key}).Int64()
+	if err != nil {
+		return nil, err
+	}
+	if attempts < 0 {
+		return nil, errors.New("登录已过期，请重新登录")
+	}
+	if attempts > loginChallengeMaxAttempts {
+		global.Redis.Del(ctx, key)
+		return nil, errors.New("验证失败次数过多，请重新登录")
+	}
+
+	data, err := global.Redis.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseUint(data["user_id"], 10, 32)
+
+	var user entity.User
+	if err := s.getDB(ctx).First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+	if err := checkUserStatus(&user); err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, errors.New("两步验证未开启，请重新登录")
+	}
+
+	// 验证码错误计入账号的登录失败次数
+	account := loginAccountID(user.ID, "")
+	if err := checkLoginAllowed(ctx, account, req.IP); err != nil {
+		return nil, err
+	}
+	if err := s.verifyCode(ctx, &user, req.Code); err != nil {
+		if err := recordLoginFailure(ctx, account, req.IP); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+
+	// 挑战只能使用一次，并发请求中仅删除成功的一方可以登录
+	deleted, err := global.Redis.Del(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, errors.New("登录已过期，请重新登录")
+	}
+
+	if err := clearLoginFailures(ctx, account); err != nil {
+		return nil, err
+	}
+
+	// 优先使用密码验证时记录的设备信息
+	ip, userAgent := data["ip"], data["user_agent"]
+	if ip == "" {
+		ip = req.IP
+	}
+	if userAgent == "" {
+		userAgent = req.UserAgent
+	}
+	tokens, err := newTokenFamily(ctx, user.ID, ip, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.UserLoginResponse{
+		TokenResponse: tokens,
+		User:          &user,
+	}, nil
+}
+
+// verifyCode 校验TOTP验证码，格式不符时按恢复码校验
+func (s *TwoFactorService) verifyCode(ctx context.Context, user *entity.User, code string) error {
+	code = strings.TrimSpace(code)
+	if _, err := strconv.Atoi(code); err == nil && len(code) == 6 {
+		return useTOTPCode(ctx, user.ID, user.TOTPSecret, code)
+	}
+
+	// 恢复码使用后立即标记，条件更新保证并发时只能成功一次
+	result := s.getDB(ctx).Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("验证码错误")
+	}
+	return nil
+}
+
+// resetRecoveryCodes 删除用户的旧恢复码并生成新的恢复码
+func (s *TwoFactorService) resetRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	db := s.getDB(ctx)
+
+	if err := db.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]entity.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := utils.RandomString(5)
+		if err != nil {
+			return nil, err
+		}
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		records = append(records, entity.RecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+	}
+	if err := db.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// useTOTPCode 校验TOTP验证码，同一验证码只能使用一次
+func useTOTPCode(ctx context.Context, userID uint, secret, code string) error {
+	step, ok := utils.ValidateTOTP(secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return errors.New("验证码错误")
+	}
+	fresh, err := global.Redis.SetNX(ctx, totpUsedKey(userID, step), 1, totpUsedTTL).Result()
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return errors.New("验证码已使用，请等待下一个验证码")
+	}
+	return nil
+}
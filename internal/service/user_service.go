@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"blog/internal/global"
+	"blog/internal/rbac"
 	"blog/internal/utils"
 	"blog/model/entity"
 	"blog/model/req"
@@ -36,6 +39,11 @@ func (s *UserService) Register(ctx context.Context, req *req.UserRegisterRequest
 		return nil, errors.New("用户名或邮箱已存在")
 	}
 
+	// 校验密码强度
+	if err := checkPasswordPolicy(req.Password); err != nil {
+		return nil, err
+	}
+
 	// 加密密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -48,6 +56,7 @@ func (s *UserService) Register(ctx context.Context, req *req.UserRegisterRequest
 		Email:    req.Email,
 		Password: string(hashedPassword),
 		Nickname: req.Nickname,
+		Role:     rbac.DefaultRole,
 		Status:   1,
 	}
 
@@ -59,6 +68,11 @@ func (s *UserService) Register(ctx context.Context, req *req.UserRegisterRequest
 		return nil, err
 	}
 
+	// 发送验证邮件，失败时用户可重新发送
+	if err := sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
 	return user, nil
 }
 
@@ -67,44 +81,106 @@ func (s *UserService) Login(ctx context.Context, req *req.UserLoginRequest) (*re
 	db := s.getDB(ctx)
 
 	var user entity.User
+	found := true
 	if err := db.Where("username = ? OR email = ?", req.Username, req.Username).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户不存在")
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
 		}
+		found = false
+	}
+
+	// 失败次数过多的账号或IP需等待退避或锁定结束
+	account := loginAccountID(user.ID, req.Username)
+	if err := checkLoginAllowed(ctx, account, req.IP); err != nil {
 		return nil, err
 	}
 
-	// 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("密码错误")
+	// 验证密码，用户不存在时同样比较一次，保证响应一致
+	hash := []byte(user.Password)
+	if !found {
+		hash = dummyPasswordHash()
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(req.Password)); err != nil || !found {
+		if err := recordLoginFailure(ctx, account, req.IP); err != nil {
+			return nil, err
+		}
+		return nil, errInvalidCredentials
 	}
 
-	// 生成JWT token
-	token, err := utils.GenerateToken(user.ID)
-	if err != nil {
+	// 检查账号状态
+	if err := checkUserStatus(&user); err != nil {
 		return nil, err
 	}
+	if global.Config.Account.RequireEmailVerification && user.EmailVerifiedAt == nil {
+		return nil, errors.New("邮箱未验证，请先完成邮箱验证")
+	}
 
-	// 将token存储到Redis，支持多设备登录
-	tokenKey := fmt.Sprintf("token:%s", token)
-	userTokensKey := fmt.Sprintf("user_tokens:%d", user.ID)
+	// 开启两步验证时先返回登录挑战，验证通过后再签发token
+	// 此时不清除失败计数，避免已知密码时通过反复登录绕过验证码的错误次数限制
+	if user.TOTPEnabled {
+		challenge, err := newLoginChallenge(ctx, user.ID, req.IP, req.UserAgent)
+		if err != nil {
+			return nil, err
+		}
+		return &resp.UserLoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		}, nil
+	}
 
-	// 获取JWT过期时间
-	expireDuration := utils.GetJWTExpireDuration()
+	if err := clearLoginFailures(ctx, account); err != nil {
+		return nil, err
+	}
 
-	// 存储token信息，包含用户ID
-	global.Redis.Set(ctx, tokenKey, user.ID, expireDuration)
-	// 将token添加到用户的token集合中
-	global.Redis.SAdd(ctx, userTokensKey, token)
-	// 设置用户token集合的过期时间
-	global.Redis.Expire(ctx, userTokensKey, expireDuration)
+	// 签发access token和refresh token，开启新的令牌族
+	tokens, err := newTokenFamily(ctx, user.ID, req.IP, req.UserAgent)
+	if err != nil {
+		return nil, err
+	}
 
 	return &resp.UserLoginResponse{
-		Token: token,
-		User:  &user,
+		TokenResponse: tokens,
+		User:          &user,
 	}, nil
 }
 
+// RefreshToken 使用refresh token换取新的access token和refresh token
+func (s *UserService) RefreshToken(ctx context.Context, req *req.TokenRefreshRequest) (*resp.TokenResponse, error) {
+	userID, family, err := useRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// 用户已被删除时注销令牌族
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := revokeTokenFamily(ctx, userID, family); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+
+	// 检查账号状态
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	// 在同一令牌族下签发新令牌
+	tokens, err := issueTokens(ctx, userID, family)
+	if err != nil {
+		return nil, err
+	}
+
+	// 刷新视为会话活跃，延长会话有效期
+	if err := touchSession(ctx, family, req.IP, refreshExpireDuration()); err != nil {
+		log.Printf("Failed to update session %s: %v", family, err)
+	}
+	return tokens, nil
+}
+
 // GetUserByID 根据ID获取用户
 func (s *UserService) GetUserByID(ctx context.Context, id uint) (*entity.User, error) {
 	db := s.getDB(ctx)
@@ -116,12 +192,12 @@ func (s *UserService) GetUserByID(ctx context.Context, id uint) (*entity.User, e
 	return &user, nil
 }
 
-// ValidateToken 验证token
-func (s *UserService) ValidateToken(token string) (*entity.User, error) {
+// ValidateToken 验证token，返回用户及token声明
+func (s *UserService) ValidateToken(token string) (*entity.User, *utils.Claims, error) {
 	// 1. 解析JWT token获取用户ID
 	claims, err := utils.ParseToken(token)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// 2. 检查Redis中是否存在该token
@@ -129,16 +205,73 @@ func (s *UserService) ValidateToken(token string) (*entity.User, error) {
 	tokenKey := fmt.Sprintf("token:%s", token)
 	userIDStr, err := global.Redis.Get(ctx, tokenKey).Result()
 	if err != nil {
-		return nil, errors.New("token已过期或无效")
+		return nil, nil, errors.New("token已过期或无效")
 	}
 
 	// 3. 验证token中的用户ID与Redis中存储的是否一致
 	if fmt.Sprintf("%d", claims.UserID) != userIDStr {
-		return nil, errors.New("token无效")
+		return nil, nil, errors.New("token无效")
 	}
 
 	// 4. 从数据库获取用户信息
-	return s.GetUserByID(context.Background(), claims.UserID)
+	user, err := s.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 5. 检查账号状态，禁用或暂停期间的token一律拒绝
+	if err := checkUserStatus(user); err != nil {
+		return nil, nil, err
+	}
+
+	// 6. 更新会话最近活跃时间，失败不影响认证
+	if claims.SessionID != "" {
+		if err := touchSession(ctx, claims.SessionID, "", 0); err != nil {
+			log.Printf("Failed to update session %s: %v", claims.SessionID, err)
+		}
+	}
+
+	return user, claims, nil
+}
+
+// ValidateAccessToken 验证个人访问令牌，返回用户及令牌的有效授权范围
+// 有效授权范围为令牌授权范围与用户当前角色权限的交集，角色降级后令牌权限随之收缩
+func (s *UserService) ValidateAccessToken(ctx context.Context, token string) (*entity.User, []rbac.Permission, error) {
+	db := s.getDB(ctx)
+
+	var accessToken entity.AccessToken
+	if err := db.Where("token_hash = ?", hashAccessToken(token)).First(&accessToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("访问令牌无效")
+		}
+		return nil, nil, err
+	}
+	if accessToken.ExpiresAt != nil && time.Now().After(*accessToken.ExpiresAt) {
+		return nil, nil, errors.New("访问令牌已过期")
+	}
+
+	user, err := s.GetUserByID(ctx, accessToken.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, nil, err
+	}
+
+	scopes := make([]rbac.Permission, 0)
+	for _, perm := range rbac.ParseScopes(accessToken.Scopes) {
+		if rbac.HasPermission(user.Role, perm) {
+			scopes = append(scopes, perm)
+		}
+	}
+
+	// 更新最近使用时间，失败不影响认证
+	now := time.Now()
+	if accessToken.LastUsedAt == nil || now.Sub(*accessToken.LastUsedAt) > accessTokenTouchInterval {
+		db.Model(&accessToken).UpdateColumn("last_used_at", now)
+	}
+
+	return user, scopes, nil
 }
 
 // Logout 用户注销
@@ -149,6 +282,11 @@ func (s *UserService) Logout(ctx context.Context, token string) error {
 		return err
 	}
 
+	// 注销该token所属的令牌族，同时作废对应的refresh token
+	if claims.SessionID != "" {
+		return revokeTokenFamily(ctx, claims.UserID, claims.SessionID)
+	}
+
 	// 从Redis中删除token
 	tokenKey := fmt.Sprintf("token:%s", token)
 	userTokensKey := fmt.Sprintf("user_tokens:%d", claims.UserID)
@@ -163,6 +301,11 @@ func (s *UserService) Logout(ctx context.Context, token string) error {
 
 // LogoutAllDevices 注销用户所有设备
 func (s *UserService) LogoutAllDevices(ctx context.Context, userID uint) error {
+	// 注销所有令牌族，作废refresh token
+	if err := revokeAllTokenFamilies(ctx, userID); err != nil {
+		return err
+	}
+
 	userTokensKey := fmt.Sprintf("user_tokens:%d", userID)
 
 	// 获取用户所有token
@@ -183,8 +326,209 @@ func (s *UserService) LogoutAllDevices(ctx context.Context, userID uint) error {
 	return nil
 }
 
+// UpdateUserRole 修改用户角色，管理员不能修改自己的角色
+func (s *UserService) UpdateUserRole(ctx context.Context, operatorID, userID uint, role string) (*entity.User, error) {
+	db := s.getDB(ctx)
+
+	if !rbac.IsValidRole(role) {
+		return nil, errors.New("角色不存在")
+	}
+	if operatorID == userID {
+		return nil, errors.New("不能修改自己的角色")
+	}
+
+	var user entity.User
+	if err := db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+
+	if err := db.Model(&user).Update("role", role).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// EnsureAdmins 为指定用户名的用户授予管理员角色，用户不存在时跳过
+func (s *UserService) EnsureAdmins(ctx context.Context, usernames []string) error {
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	db := s.getDB(ctx)
+	result := db.Model(&entity.User{}).
+		Where("username IN ? AND role <> ?", usernames, rbac.RoleAdmin).
+		Update("role", rbac.RoleAdmin)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Granted admin role to %d users", result.RowsAffected)
+	}
+	return nil
+}
+
+// ChangePassword 修改密码，校验当前密码后重新加密，并注销除当前会话外的所有token
+func (s *UserService) ChangePassword(ctx context.Context, userID uint, currentSessionID, currentToken string, req *req.PasswordChangeRequest) error {
+	db := s.getDB(ctx)
+
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
+		return errors.New("当前密码错误")
+	}
+	if req.NewPassword == req.OldPassword {
+		return errors.New("新密码不能与当前密码相同")
+	}
+	if err := checkPasswordPolicy(req.NewPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := db.Model(user).Update("password", string(hashedPassword)).Error; err != nil {
+		return err
+	}
+
+	if req.RevokeAccessTokens {
+		if err := revokeAccessTokens(db, userID); err != nil {
+			return err
+		}
+	}
+
+	return revokeOtherTokens(ctx, userID, currentSessionID, currentToken)
+}
+
+// DisableUser 禁用用户，并立即注销其所有token
+func (s *UserService) DisableUser(ctx context.Context, operatorID, userID uint, req *req.UserDisableRequest) (*entity.User, error) {
+	return s.setUserStatus(ctx, operatorID, userID, map[string]interface{}{
+		"status":          0,
+		"status_reason":   req.Reason,
+		"suspended_until": nil,
+	})
+}
+
+// SuspendUser 暂停用户至指定时间，未指定时间时无限期暂停，并立即注销其所有token
+func (s *UserService) SuspendUser(ctx context.Context, operatorID, userID uint, req *req.UserSuspendRequest) (*entity.User, error) {
+	if req.Until != nil && !req.Until.After(time.Now()) {
+		return nil, errors.New("暂停截止时间必须晚于当前时间")
+	}
+	return s.setUserStatus(ctx, operatorID, userID, map[string]interface{}{
+		"status":          2,
+		"status_reason":   req.Reason,
+		"suspended_until": req.Until,
+	})
+}
+
+// EnableUser 恢复用户为正常状态
+func (s *UserService) EnableUser(ctx context.Context, operatorID, userID uint) (*entity.User, error) {
+	return s.setUserStatus(ctx, operatorID, userID, map[string]interface{}{
+		"status":          1,
+		"status_reason":   "",
+		"suspended_until": nil,
+	})
+}
+
+// setUserStatus 更新用户状态，非正常状态时注销用户的所有token
+func (s *UserService) setUserStatus(ctx context.Context, operatorID, userID uint, updates map[string]interface{}) (*entity.User, error) {
+	db := s.getDB(ctx)
+
+	if operatorID == userID {
+		return nil, errors.New("不能修改自己的账号状态")
+	}
+
+	var user entity.User
+	if err := db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+
+	if err := db.Model(&user).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	if err := db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	if user.Status != 1 {
+		if err := s.LogoutAllDevices(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return &user, nil
+}
+
+// UnlockLogin 清除用户因登录失败产生的退避和锁定
+func (s *UserService) UnlockLogin(ctx context.Context, userID uint) error {
+	if _, err := s.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+	return clearLoginFailures(ctx, loginAccountID(userID, ""))
+}
+
+// checkUserStatus 检查账号是否可用，暂停到期后自动恢复可用
+func checkUserStatus(user *entity.User) error {
+	switch user.Status {
+	case 1:
+		return nil
+	case 2:
+		if user.SuspendedUntil != nil && time.Now().After(*user.SuspendedUntil) {
+			return nil
+		}
+		msg := "账号已被暂停使用"
+		if user.SuspendedUntil != nil {
+			msg += "，截止至" + user.SuspendedUntil.Format("2006-01-02 15:04:05")
+		}
+		if user.StatusReason != "" {
+			msg += "，原因：" + user.StatusReason
+		}
+		return errors.New(msg)
+	default:
+		msg := "账号已被禁用"
+		if user.StatusReason != "" {
+			msg += "，原因：" + user.StatusReason
+		}
+		return errors.New(msg)
+	}
+}
+
 // UpdateProfile 更新用户资料
 func (s *UserService) UpdateProfile(ctx context.Context, userID uint, updates map[string]interface{}) error {
 	db := s.getDB(ctx)
-	return db.Model(&entity.User{}).Where("id = ?", userID).Updates(updates).Error
+
+	var user entity.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	// 修改邮箱后需要重新验证
+	email, _ := updates["email"].(string)
+	emailChanged := email != "" && email != user.Email
+	if emailChanged {
+		updates["email_verified_at"] = nil
+	}
+
+	if err := db.Model(&user).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	if emailChanged {
+		user.Email = email
+		if err := sendVerificationEmail(ctx, &user); err != nil {
+			log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+	return nil
 }
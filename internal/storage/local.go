@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// LocalStorage 本地文件系统存储实现，文件由本服务的静态文件路由对外提供
+type LocalStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStorage 创建本地存储，dir为存储根目录，baseURL为访问地址前缀
+func NewLocalStorage(dir, baseURL string) (*LocalStorage, error) {
+	if dir == "" {
+		return nil, errors.New("storage: local dir is empty")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{dir: dir, baseURL: baseURL}, nil
+}
+
+// Dir 存储根目录
+func (s *LocalStorage) Dir() string {
+	return s.dir
+}
+
+// Put 保存对象，先写入临时文件再重命名，避免读到未写完的文件
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// Get 读取对象
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	filePath, _ := s.path(key)
+	f, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+// Delete 删除对象
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Stat 获取对象信息，本地文件不保存MIME类型，按扩展名推断
+func (s *LocalStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if fi.IsDir() {
+		return nil, ErrNotFound
+	}
+
+	key, _ = cleanKey(key)
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &ObjectInfo{Key: key, Size: fi.Size(), ContentType: contentType, ModTime: fi.ModTime()}, nil
+}
+
+// URL 对象的访问地址
+func (s *LocalStorage) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
+
+// path key对应的本地文件路径
+func (s *LocalStorage) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
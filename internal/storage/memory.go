@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryObject 内存中的对象
+type memoryObject struct {
+	data []byte
+	info ObjectInfo
+}
+
+// MemoryStorage 进程内存储实现，重启后数据丢失，用于测试和本地开发
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string]*memoryObject
+}
+
+// NewMemoryStorage 创建内存存储
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		objects: make(map[string]*memoryObject),
+	}
+}
+
+// Put 保存对象
+func (s *MemoryStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = &memoryObject{
+		data: data,
+		info: ObjectInfo{Key: key, Size: int64(len(data)), ContentType: contentType, ModTime: time.Now()},
+	}
+	return nil
+}
+
+// Get 读取对象
+func (s *MemoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	info := obj.info
+	return io.NopCloser(bytes.NewReader(obj.data)), &info, nil
+}
+
+// Delete 删除对象
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+	return nil
+}
+
+// Stat 获取对象信息
+func (s *MemoryStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	info := obj.info
+	return &info, nil
+}
+
+// URL 对象的访问地址，内存中的对象无法通过HTTP访问
+func (s *MemoryStorage) URL(key string) string {
+	return "memory://" + strings.TrimPrefix(key, "/")
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// MinioStorage 基于MinIO/S3对象存储的实现，bucket设置为公共读取
+type MinioStorage struct {
+	client  *minio.Client
+	bucket  string
+	baseURL string
+}
+
+// NewMinioStorage 创建MinIO存储，bucket不存在时自动创建并设置公共读取权限
+// baseURL为空时使用endpoint/bucket作为访问地址前缀
+func NewMinioStorage(ctx context.Context, endpoint, accessKeyID, secretAccessKey string, useSSL bool, bucket, baseURL string) (*MinioStorage, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+		Secure: useSSL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查并创建bucket
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
+			return nil, err
+		}
+	}
+
+	if baseURL == "" {
+		protocol := "http"
+		if useSSL {
+			protocol = "https"
+		}
+		baseURL = fmt.Sprintf("%s://%s/%s", protocol, endpoint, bucket)
+	}
+
+	s := &MinioStorage{client: client, bucket: bucket, baseURL: baseURL}
+	if err := s.SetPublicPolicy(ctx); err != nil {
+		// 不返回错误，bucket已可用，只是无法匿名访问
+		log.Printf("Warning: Failed to set bucket policy: %v", err)
+	}
+	return s, nil
+}
+
+// SetPublicPolicy 设置bucket为公共读取权限，可用于修复已存在的bucket权限问题
+func (s *MinioStorage) SetPublicPolicy(ctx context.Context) error {
+	policy := `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": {"AWS": ["*"]},
+				"Action": ["s3:GetObject"],
+				"Resource": ["arn:aws:s3:::` + s.bucket + `/*"]
+			}
+		]
+	}`
+	return s.client.SetBucketPolicy(ctx, s.bucket, policy)
+}
+
+// Put 保存对象
+func (s *MinioStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+// Get 读取对象
+func (s *MinioStorage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, info.Key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, s.convertError(err)
+	}
+	return obj, info, nil
+}
+
+// Delete 删除对象，S3删除不存在的对象不会报错
+func (s *MinioStorage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// Stat 获取对象信息
+func (s *MinioStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, s.convertError(err)
+	}
+	return &ObjectInfo{Key: key, Size: stat.Size, ContentType: stat.ContentType, ModTime: stat.LastModified}, nil
+}
+
+// URL 对象的访问地址
+func (s *MinioStorage) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
+
+// convertError 将对象不存在的错误转换为ErrNotFound
+func (s *MinioStorage) convertError(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
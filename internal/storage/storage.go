@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("storage: object not found")
+
+// ObjectInfo 对象信息
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage 文件存储接口，key为以/分隔的相对路径，如uploads/2006-01-02/a.png
+type Storage interface {
+	// Put 保存对象，已存在时覆盖
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取对象，调用方负责关闭返回的ReadCloser；不存在时返回ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// Stat 获取对象信息，不存在时返回ErrNotFound
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// URL 对象的公开访问地址
+	URL(key string) string
+}
+
+// cleanKey 规范化key，拒绝跳出存储根目录的路径
+func cleanKey(key string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", errors.New("storage: invalid object key " + key)
+	}
+	return cleaned, nil
+}
+
+// joinURL 拼接访问地址前缀和key
+func joinURL(baseURL, key string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimPrefix(key, "/")
+}
@@ -0,0 +1,172 @@
+package utils
+
+import "strings"
+
+// DiffLine 行级差异
+type DiffLine struct {
+	Type string `json:"type"` // equal-未变化, insert-新增, delete-删除
+	Text string `json:"text"`
+}
+
+// maxDiffRounds 单次二分查找的最大轮数，超过时该段按整体删除再新增输出，限制差异很大时的计算量
+const maxDiffRounds = 2000
+
+// DiffLines 使用线性空间的Myers算法(分治查找中间点)计算两段文本的行级差异
+func DiffLines(oldText, newText string) []DiffLine {
+	d := &differ{a: splitLines(oldText), b: splitLines(newText)}
+	d.diff(0, len(d.a), 0, len(d.b))
+	return d.lines
+}
+
+// differ 差异计算状态，按顺序收集结果
+type differ struct {
+	a, b  []string
+	lines []DiffLine
+}
+
+// diff 计算a[aLo:aHi]与b[bLo:bHi]的差异
+func (d *differ) diff(aLo, aHi, bLo, bHi int) {
+	// 去掉公共前缀和后缀
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.emit("equal", d.a[aLo])
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-suffix-1] == d.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	switch {
+	case aLo == aHi:
+		for _, line := range d.b[bLo:bHi] {
+			d.emit("insert", line)
+		}
+	case bLo == bHi:
+		for _, line := range d.a[aLo:aHi] {
+			d.emit("delete", line)
+		}
+	default:
+		if x, y, ok := d.bisect(aLo, aHi, bLo, bHi); ok {
+			d.diff(aLo, x, bLo, y)
+			d.diff(x, aHi, y, bHi)
+		} else {
+			d.replace(aLo, aHi, bLo, bHi)
+		}
+	}
+
+	for _, line := range d.a[aHi : aHi+suffix] {
+		d.emit("equal", line)
+	}
+}
+
+// bisect 同时从两端搜索最短编辑路径，返回两端路径相遇的位置
+// 只保存当前一轮的v，空间与文本长度成线性关系；超过maxDiffRounds轮时放弃
+func (d *differ) bisect(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	a, b := d.a[aLo:aHi], d.b[bLo:bHi]
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	vOffset := maxD
+	vLength := 2*maxD + 2
+	v1 := make([]int, vLength)
+	v2 := make([]int, vLength)
+	for i := range v1 {
+		v1[i] = -1
+		v2[i] = -1
+	}
+	v1[vOffset+1] = 0
+	v2[vOffset+1] = 0
+
+	delta := n - m
+	// 总长度为奇数时正向路径先与反向路径相遇
+	front := delta%2 != 0
+	k1Start, k1End, k2Start, k2End := 0, 0, 0, 0
+	for step := 0; step < maxD && step < maxDiffRounds; step++ {
+		// 正向搜索
+		for k1 := -step + k1Start; k1 <= step-k1End; k1 += 2 {
+			k1Offset := vOffset + k1
+			var x1 int
+			if k1 == -step || (k1 != step && v1[k1Offset-1] < v1[k1Offset+1]) {
+				x1 = v1[k1Offset+1]
+			} else {
+				x1 = v1[k1Offset-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			v1[k1Offset] = x1
+			switch {
+			case x1 > n:
+				k1End += 2
+			case y1 > m:
+				k1Start += 2
+			case front:
+				k2Offset := vOffset + delta - k1
+				if k2Offset >= 0 && k2Offset < vLength && v2[k2Offset] != -1 && x1 >= n-v2[k2Offset] {
+					return aLo + x1, bLo + y1, true
+				}
+			}
+		}
+
+		// 反向搜索
+		for k2 := -step + k2Start; k2 <= step-k2End; k2 += 2 {
+			k2Offset := vOffset + k2
+			var x2 int
+			if k2 == -step || (k2 != step && v2[k2Offset-1] < v2[k2Offset+1]) {
+				x2 = v2[k2Offset+1]
+			} else {
+				x2 = v2[k2Offset-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			v2[k2Offset] = x2
+			switch {
+			case x2 > n:
+				k2End += 2
+			case y2 > m:
+				k2Start += 2
+			case !front:
+				k1Offset := vOffset + delta - k2
+				if k1Offset >= 0 && k1Offset < vLength && v1[k1Offset] != -1 {
+					x1 := v1[k1Offset]
+					y1 := vOffset + x1 - k1Offset
+					if x1 >= n-x2 {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// replace 整段删除后整段新增
+func (d *differ) replace(aLo, aHi, bLo, bHi int) {
+	for _, line := range d.a[aLo:aHi] {
+		d.emit("delete", line)
+	}
+	for _, line := range d.b[bLo:bHi] {
+		d.emit("insert", line)
+	}
+}
+
+// emit 追加一行差异
+func (d *differ) emit(typ, text string) {
+	d.lines = append(d.lines, DiffLine{Type: typ, Text: text})
+}
+
+// splitLines 按行拆分文本，统一换行符
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
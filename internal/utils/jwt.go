@@ -7,34 +7,67 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("default-jwt-secret-please-change-in-production")
-var jwtExpireHour = 24 // 默认24小时
+// jwtSecret HMAC密钥，由InitJWT从配置设置，没有默认值
+var jwtSecret []byte
+var jwtExpireMinute = 15 // 默认15分钟
+
+// keySet JWT签名密钥集合，由InitJWT设置
+var keySet *KeySet
 
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID    uint   `json:"user_id"`
+	SessionID string `json:"sid,omitempty"` // 令牌族ID，同一次登录刷新出的令牌共用
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成JWT token
-func GenerateToken(userID uint) (string, error) {
+// GenerateToken 生成短期有效的JWT access token
+func GenerateToken(userID uint, sessionID string) (string, error) {
+	jti, err := RandomString(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(jwtExpireHour) * time.Hour * 30)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(GetJWTExpireDuration())),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return SignJWT(claims)
+}
+
+// SignJWT 使用当前签名密钥签发JWT，header中携带kid
+func SignJWT(claims jwt.Claims) (string, error) {
+	key, err := GetSigningKey()
+	if err != nil {
+		return "", err
+	}
+	return key.Sign(claims)
 }
 
 // ParseToken 解析JWT token
 func ParseToken(tokenString string) (*Claims, error) {
+	if keySet == nil {
+		return nil, errors.New("jwt signing key not initialized")
+	}
+	// 按kid选择验证密钥，并要求签名算法与密钥一致
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		key := keySet.Lookup(kid)
+		if key == nil {
+			return nil, errors.New("unknown signing key")
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, errors.New("signing method does not match key")
+		}
+		return key.Public(), nil
+	}, jwt.WithValidMethods([]string{AlgRS256, AlgES256, AlgEdDSA}))
 
 	if err != nil {
 		return nil, err
@@ -47,17 +80,50 @@ func ParseToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
-// SetJWTSecret 设置JWT密钥
+// SetJWTSecret 设置HMAC密钥，用于邮件链接等数据签名
 func SetJWTSecret(secret string) {
 	jwtSecret = []byte(secret)
 }
 
-// SetJWTExpireHour 设置JWT过期时间（小时）
-func SetJWTExpireHour(hours int) {
-	jwtExpireHour = hours
+// SetKeySet 设置JWT签名密钥集合
+func SetKeySet(set *KeySet) {
+	keySet = set
+}
+
+// GetSigningKey 获取当前JWT签名密钥
+func GetSigningKey() (*SigningKey, error) {
+	if keySet == nil {
+		return nil, errors.New("jwt signing key not initialized")
+	}
+	key := keySet.Current()
+	if key == nil {
+		return nil, errors.New("jwt signing key not initialized")
+	}
+	return key, nil
+}
+
+// GetJWKS 获取用于验证JWT签名的公钥集合
+func GetJWKS() (*JWKSet, error) {
+	if keySet == nil {
+		return nil, errors.New("jwt signing key not initialized")
+	}
+	return keySet.JWKS(), nil
+}
+
+// RefreshSigningKeys 重新加载签名密钥，到期时轮换
+func RefreshSigningKeys() error {
+	if keySet == nil {
+		return errors.New("jwt signing key not initialized")
+	}
+	return keySet.Refresh()
+}
+
+// SetJWTExpireMinute 设置access token过期时间（分钟）
+func SetJWTExpireMinute(minutes int) {
+	jwtExpireMinute = minutes
 }
 
-// GetJWTExpireDuration 获取JWT过期时间（Duration）
+// GetJWTExpireDuration 获取access token过期时间（Duration）
 func GetJWTExpireDuration() time.Duration {
-	return time.Duration(jwtExpireHour) * time.Hour * 30
+	return time.Duration(jwtExpireMinute) * time.Minute
 }
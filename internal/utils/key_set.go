@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 密钥文件名为jwt-<创建时间>.pem，创建时间用于判断启用、轮换和过期
+const (
+	keyFilePrefix     = "jwt-"
+	keyFileSuffix     = ".pem"
+	keyFileTimeLayout = "20060102T150405Z"
+)
+
+// KeySet JWT签名密钥集合，支持多把验证密钥和定时轮换
+// 最新生成的密钥先发布到JWKS，经过启用延迟后才用于签名，便于其他实例和验证方提前加载；
+// 被替换的旧密钥在保留期内继续用于验证，之后从目录中删除
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []*SigningKey // 按创建时间升序
+
+	dir             string        // 密钥目录，为空时只保存在内存中
+	algorithm       string        // 新密钥的签名算法
+	rotateInterval  time.Duration // 轮换间隔，0表示不自动轮换
+	activationDelay time.Duration // 新密钥发布后延迟启用的时间
+	retention       time.Duration // 旧密钥被替换后继续用于验证的时间
+}
+
+// NewKeySet 加载密钥目录，目录中没有密钥时生成新密钥
+func NewKeySet(dir, algorithm string, rotateInterval, activationDelay, retention time.Duration) (*KeySet, error) {
+	switch algorithm {
+	case AlgRS256, AlgES256, AlgEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm: %s", algorithm)
+	}
+
+	s := &KeySet{
+		dir:             dir,
+		algorithm:       algorithm,
+		rotateInterval:  rotateInterval,
+		activationDelay: activationDelay,
+		retention:       retention,
+	}
+	if err := s.Refresh(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Refresh 重新加载密钥目录，到期时生成新密钥并清理超过保留期的旧密钥
+// 多实例部署时各实例共享密钥目录，定时调用以加载其他实例生成的密钥
+func (s *KeySet) Refresh() error {
+	keys, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if len(keys) == 0 || s.rotateInterval > 0 && now.Sub(keys[len(keys)-1].CreatedAt) >= s.rotateInterval {
+		key, err := GenerateSigningKey(s.algorithm)
+		if err != nil {
+			return err
+		}
+		key.CreatedAt = now.UTC().Truncate(time.Second)
+		if err := s.save(key); err != nil {
+			// 其他实例同时生成了密钥，以目录中的为准
+			if errors.Is(err, os.ErrExist) {
+				return s.Refresh()
+			}
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	// 清理超过保留期的旧密钥，旧密钥在下一把密钥启用时被替换
+	kept := keys[:0]
+	for i, key := range keys {
+		if i < len(keys)-1 && now.After(keys[i+1].CreatedAt.Add(s.activationDelay+s.retention)) {
+			if err := s.remove(key); err != nil {
+				return err
+			}
+			continue
+		}
+		kept = append(kept, key)
+	}
+
+	s.mu.Lock()
+	s.keys = kept
+	s.mu.Unlock()
+	return nil
+}
+
+// Current 当前用于签名的密钥：已过启用延迟的最新密钥，没有时使用最早的密钥
+func (s *KeySet) Current() *SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	activeBefore := time.Now().Add(-s.activationDelay)
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if !s.keys[i].CreatedAt.After(activeBefore) {
+			return s.keys[i]
+		}
+	}
+	if len(s.keys) == 0 {
+		return nil
+	}
+	return s.keys[0]
+}
+
+// Lookup 按kid查找验证密钥
+func (s *KeySet) Lookup(kid string) *SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.ID == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+// JWKS 所有验证密钥的公钥，包含尚未启用的新密钥
+func (s *KeySet) JWKS() *JWKSet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := &JWKSet{Keys: make([]JWK, 0, len(s.keys))}
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		set.Keys = append(set.Keys, s.keys[i].JWK())
+	}
+	return set
+}
+
+// load 读取密钥目录中的所有密钥，按创建时间升序排列
+func (s *KeySet) load() ([]*SigningKey, error) {
+	if s.dir == "" {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return append([]*SigningKey(nil), s.keys...), nil
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	var keys []*SigningKey
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, keyFilePrefix) || !strings.HasSuffix(name, keyFileSuffix) {
+			continue
+		}
+		createdAt, err := time.Parse(keyFileTimeLayout, strings.TrimSuffix(strings.TrimPrefix(name, keyFilePrefix), keyFileSuffix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key file name %s: %w", name, err)
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParseSigningKey(data, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("load key file %s: %w", name, err)
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// save 保存新密钥，仅当前用户可读，文件已存在时返回os.ErrExist
+// 先写入临时文件再硬链接到正式文件名，避免其他实例读到未写完的文件
+func (s *KeySet) save(key *SigningKey) error {
+	if s.dir == "" {
+		return nil
+	}
+
+	data, err := key.MarshalPEM()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".jwt-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Link(tmp.Name(), s.keyPath(key))
+}
+
+// remove 删除过期的密钥文件，其他实例已删除时忽略
+func (s *KeySet) remove(key *SigningKey) error {
+	if s.dir == "" {
+		return nil
+	}
+	if err := os.Remove(s.keyPath(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// keyPath 密钥文件路径
+func (s *KeySet) keyPath(key *SigningKey) string {
+	return filepath.Join(s.dir, keyFilePrefix+key.CreatedAt.UTC().Format(keyFileTimeLayout)+keyFileSuffix)
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"blog/internal/global"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// unlockScript 仅当锁仍由自己持有时才释放，避免误删其他实例的锁
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// TryLock 尝试获取Redis分布式锁，用于多实例部署下的后台任务互斥
+// 获取成功时返回释放锁的函数，锁被占用时返回 ok=false
+func TryLock(ctx context.Context, key string, ttl time.Duration) (unlock func(), ok bool, err error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, false, err
+	}
+	value := hex.EncodeToString(buf)
+
+	ok, err = global.Redis.SetNX(ctx, key, value, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	unlock = func() {
+		unlockScript.Run(context.Background(), global.Redis, []string{key}, value)
+	}
+	return unlock, true, nil
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// sniffLen 内容类型检测需要读取的字节数
+const sniffLen = 512
+
+// oleMagic OLE2复合文档(doc、xls、ppt)文件头
+var oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// zipMagic zip压缩包文件头，OOXML文档(docx、xlsx、pptx)是zip压缩包
+var zipMagic = []byte("PK\x03\x04")
+
+// oleTypes OLE2复合文档仅凭文件头无法区分具体文档类型，按扩展名细化
+var oleTypes = map[string]string{
+	".doc": "application/msword",
+	".xls": "application/vnd.ms-excel",
+	".ppt": "application/vnd.ms-powerpoint",
+}
+
+// ooxmlType OOXML文档类型及其必须包含的主文档部件
+type ooxmlType struct {
+	mime     string
+	mainPart string
+}
+
+// ooxmlTypes 按扩展名区分的OOXML文档类型
+var ooxmlTypes = map[string]ooxmlType{
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "word/document.xml"},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xl/workbook.xml"},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", "ppt/presentation.xml"},
+}
+
+// ooxmlContentTypesPart 所有OOXML文档都必须包含的部件
+const ooxmlContentTypesPart = "[Content_Types].xml"
+
+// DetectContentType 根据文件内容检测MIME类型，不信任扩展名
+// 只有doc、docx等容器格式会参考扩展名区分具体类型：OLE2文档扩展名不符时返回容器类型，
+// zip压缩包须包含[Content_Types].xml和扩展名对应的主文档部件才视为OOXML文档，否则返回application/zip
+func DetectContentType(r io.ReaderAt, size int64, ext string) (string, error) {
+	head := make([]byte, sniffLen)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	head = head[:n]
+	ext = strings.ToLower(ext)
+
+	if bytes.HasPrefix(head, oleMagic) {
+		if contentType, ok := oleTypes[ext]; ok {
+			return contentType, nil
+		}
+		return "application/x-ole-storage", nil
+	}
+	if bytes.HasPrefix(head, zipMagic) {
+		if t, ok := ooxmlTypes[ext]; ok && zipContains(r, size, ooxmlContentTypesPart, t.mainPart) {
+			return t.mime, nil
+		}
+		return "application/zip", nil
+	}
+
+	return sniffContentType(head), nil
+}
+
+// zipContains 解析zip中央目录，判断是否包含所有指定的条目，无法解析时返回false
+func zipContains(r io.ReaderAt, size int64, names ...string) bool {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return false
+	}
+
+	entries := make(map[string]bool, len(zr.File))
+	for _, f := range zr.File {
+		entries[f.Name] = true
+	}
+	for _, name := range names {
+		if !entries[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// sniffContentType 检测文件头对应的MIME类型，去掉charset等参数
+func sniffContentType(head []byte) string {
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return contentType
+}
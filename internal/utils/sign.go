@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Sign 使用JWT密钥对数据进行HMAC-SHA256签名
+func Sign(data string) string {
+	mac := hmac.New(sha256.New, jwtSecret)
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySign 校验数据签名
+func VerifySign(data, signature string) bool {
+	return hmac.Equal([]byte(Sign(data)), []byte(signature))
+}
@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的JWT签名算法
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// SigningKey JWT签名密钥
+type SigningKey struct {
+	ID        string // kid，取公钥的JWK指纹(RFC 7638)
+	Algorithm string // 由私钥类型决定
+	Private   crypto.Signer
+	CreatedAt time.Time
+}
+
+// JWK JSON Web Key公钥
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC、Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// GenerateSigningKey 生成指定算法的签名密钥
+func GenerateSigningKey(alg string) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch alg {
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm: %s", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newSigningKey(private, time.Now())
+}
+
+// ParseSigningKey 解析PEM(PKCS#8)格式的私钥
+func ParseSigningKey(data []byte, createdAt time.Time) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+	return newSigningKey(private, createdAt)
+}
+
+// MarshalPEM 以PEM(PKCS#8)格式编码私钥
+func (k *SigningKey) MarshalPEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// newSigningKey 根据私钥类型确定算法，并计算kid
+func newSigningKey(private crypto.Signer, createdAt time.Time) (*SigningKey, error) {
+	key := &SigningKey{Private: private, CreatedAt: createdAt}
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < 2048 {
+			return nil, errors.New("RSA key must be at least 2048 bits")
+		}
+		key.Algorithm = AlgRS256
+	case *ecdsa.PrivateKey:
+		if private.Curve != elliptic.P256() {
+			return nil, errors.New("ECDSA key must use the P-256 curve")
+		}
+		key.Algorithm = AlgES256
+	case ed25519.PrivateKey:
+		key.Algorithm = AlgEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", private)
+	}
+
+	thumbprint, err := key.thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	key.ID = thumbprint
+	return key, nil
+}
+
+// Method 对应的JWT签名方法
+func (k *SigningKey) Method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgES256:
+		return jwt.SigningMethodES256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodRS256
+	}
+}
+
+// Sign 签发JWT，header中携带kid
+func (k *SigningKey) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.Method(), claims)
+	token.Header["kid"] = k.ID
+	return token.SignedString(k.Private)
+}
+
+// Public 公钥
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// JWK 公钥的JWK表示
+func (k *SigningKey) JWK() JWK {
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Algorithm}
+	switch pub := k.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		// 坐标按曲线长度左侧补零(RFC 7518)
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
+
+// thumbprint 计算JWK指纹(RFC 7638)，只包含必需字段且按字典序排列
+func (k *SigningKey) thumbprint() (string, error) {
+	jwk := k.JWK()
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	default:
+		return "", errors.New("unsupported key type")
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// maxSlugLength 自动生成slug的最大长度
+const maxSlugLength = 80
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// Slugify 根据标题生成slug，中文转换为不带声调的拼音
+func Slugify(title string) string {
+	args := pinyin.NewArgs()
+
+	var parts []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			parts = append(parts, string(word))
+			word = word[:0]
+		}
+	}
+
+	for _, r := range title {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word = append(word, unicode.ToLower(r))
+		case unicode.Is(unicode.Han, r):
+			flush()
+			if py := pinyin.SinglePinyin(r, args); len(py) > 0 {
+				parts = append(parts, py[0])
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	slug := strings.Join(parts, "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
+
+// IsValidSlug 校验slug格式：小写字母、数字，以短横线分隔
+func IsValidSlug(slug string) bool {
+	return slugPattern.MatchString(slug)
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// AccessToken 个人访问令牌，供脚本和CI调用API，仅保存令牌哈希
+type AccessToken struct {
+	ID         uint       `json:"id" gorm:"primarykey;comment:ID"`
+	UserID     uint       `json:"user_id" gorm:"not null;index;comment:用户ID"`
+	Name       string     `json:"name" gorm:"not null;size:50;comment:令牌名称"`
+	TokenHash  string     `json:"-" gorm:"uniqueIndex;not null;size:64;comment:令牌哈希"`
+	Prefix     string     `json:"prefix" gorm:"size:20;comment:令牌前缀，用于识别令牌"`
+	Scopes     string     `json:"scopes" gorm:"size:255;comment:授权范围，逗号分隔"`
+	ExpiresAt  *time.Time `json:"expires_at" gorm:"comment:过期时间，为空表示永不过期"`
+	LastUsedAt *time.Time `json:"last_used_at" gorm:"comment:最近使用时间"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"comment:创建时间"`
+}
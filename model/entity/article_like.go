@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+// ArticleLike 文章点赞记录，由后台任务从Redis批量回写
+type ArticleLike struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false;comment:用户ID"`
+	ArticleID uint      `json:"article_id" gorm:"primaryKey;autoIncrement:false;index;comment:文章ID"`
+	CreatedAt time.Time `json:"created_at" gorm:"comment:点赞时间"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type ArticleRevision struct {
+	ID        uint      `json:"id" gorm:"primarykey;comment:修订ID"`
+	ArticleID uint      `json:"article_id" gorm:"not null;uniqueIndex:idx_article_version;comment:文章ID"`
+	Version   int       `json:"version" gorm:"not null;uniqueIndex:idx_article_version;comment:版本号"`
+	Title     string    `json:"title" gorm:"not null;size:200;comment:文章标题"`
+	Content   string    `json:"content,omitempty" gorm:"type:longtext;comment:文章内容"`
+	Summary   string    `json:"summary" gorm:"size:500;comment:文章摘要"`
+	EditorID  uint      `json:"editor_id" gorm:"not null;comment:编辑者ID"`
+	CreatedAt time.Time `json:"created_at" gorm:"comment:创建时间"`
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// ArticleSlug 文章的所有slug(含历史slug)，用于唯一性约束和旧链接跳转
+type ArticleSlug struct {
+	ID        uint      `json:"id" gorm:"primarykey;comment:ID"`
+	ArticleID uint      `json:"article_id" gorm:"not null;index;comment:文章ID"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex;not null;size:200;comment:slug"`
+	CreatedAt time.Time `json:"created_at" gorm:"comment:创建时间"`
+}
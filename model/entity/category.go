@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+type Category struct {
+	ID          uint      `json:"id" gorm:"primarykey;comment:分类ID"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null;size:50;comment:分类名称"`
+	Description string    `json:"description" gorm:"size:255;comment:分类描述"`
+	CreatedAt   time.Time `json:"created_at" gorm:"comment:创建时间"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"comment:更新时间"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Comment struct {
+	ID        uint           `json:"id" gorm:"primarykey;comment:评论ID"`
+	ArticleID uint           `json:"article_id" gorm:"not null;index;comment:文章ID"`
+	UserID    uint           `json:"user_id" gorm:"not null;index;comment:评论者ID"`
+	ParentID  uint           `json:"parent_id" gorm:"default:0;index;comment:父评论ID,0为顶级评论"`
+	RootID    uint           `json:"root_id" gorm:"default:0;index;comment:顶级评论ID,0为顶级评论"`
+	Content   string         `json:"content" gorm:"type:text;not null;comment:评论内容"`
+	CreatedAt time.Time      `json:"created_at" gorm:"comment:创建时间"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"comment:更新时间"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index;comment:删除时间"`
+
+	// 关联
+	User     *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Children []*Comment `json:"children,omitempty" gorm:"-"`
+}
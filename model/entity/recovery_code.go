@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// RecoveryCode 两步验证恢复码，仅保存哈希，每个恢复码只能使用一次
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primarykey;comment:ID"`
+	UserID    uint       `json:"user_id" gorm:"not null;index;comment:用户ID"`
+	CodeHash  string     `json:"-" gorm:"not null;size:64;comment:恢复码哈希"`
+	UsedAt    *time.Time `json:"used_at" gorm:"comment:使用时间"`
+	CreatedAt time.Time  `json:"created_at" gorm:"comment:创建时间"`
+}
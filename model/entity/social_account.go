@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// SocialAccount 关联到本地用户的第三方登录账号
+type SocialAccount struct {
+	ID             uint      `json:"id" gorm:"primarykey;comment:ID"`
+	UserID         uint      `json:"user_id" gorm:"not null;index;comment:用户ID"`
+	Provider       string    `json:"provider" gorm:"not null;size:20;uniqueIndex:idx_provider_user;comment:第三方平台"`
+	ProviderUserID string    `json:"provider_user_id" gorm:"not null;size:100;uniqueIndex:idx_provider_user;comment:第三方平台用户ID"`
+	Email          string    `json:"email" gorm:"size:100;comment:第三方账号邮箱"`
+	CreatedAt      time.Time `json:"created_at" gorm:"comment:创建时间"`
+}
@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primarykey;comment:标签ID"`
+	Name      string    `json:"name" gorm:"uniqueIndex;not null;size:50;comment:标签名称"`
+	CreatedAt time.Time `json:"created_at" gorm:"comment:创建时间"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"comment:更新时间"`
+}
@@ -7,17 +7,23 @@ import (
 )
 
 type User struct {
-	ID        uint           `json:"id" gorm:"primarykey;comment:用户ID"`
-	Username  string         `json:"username" gorm:"uniqueIndex;not null;size:50;comment:用户名"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null;size:100;comment:邮箱地址"`
-	Password  string         `json:"-" gorm:"not null;size:255;comment:密码(加密)"`
-	Nickname  string         `json:"nickname" gorm:"size:50;comment:昵称"`
-	Avatar    string         `json:"avatar" gorm:"size:255;comment:头像URL"`
-	Bio       string         `json:"bio" gorm:"size:500;comment:个人简介"`
-	Status    int            `json:"status" gorm:"default:1;comment:状态:1-激活,0-禁用"`
-	CreatedAt time.Time      `json:"created_at" gorm:"comment:创建时间"`
-	UpdatedAt time.Time      `json:"updated_at" gorm:"comment:更新时间"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index;comment:删除时间"`
+	ID              uint           `json:"id" gorm:"primarykey;comment:用户ID"`
+	Username        string         `json:"username" gorm:"uniqueIndex;not null;size:50;comment:用户名"`
+	Email           string         `json:"email" gorm:"uniqueIndex;not null;size:100;comment:邮箱地址"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at" gorm:"comment:邮箱验证时间"`
+	Password        string         `json:"-" gorm:"not null;size:255;comment:密码(加密)"`
+	Nickname        string         `json:"nickname" gorm:"size:50;comment:昵称"`
+	Avatar          string         `json:"avatar" gorm:"size:255;comment:头像URL"`
+	Bio             string         `json:"bio" gorm:"size:500;comment:个人简介"`
+	Role            string         `json:"role" gorm:"size:20;not null;default:author;index;comment:角色:admin,editor,author,reader"`
+	Status          int            `json:"status" gorm:"default:1;comment:状态:1-激活,0-禁用,2-暂停"`
+	StatusReason    string         `json:"status_reason,omitempty" gorm:"size:255;comment:禁用或暂停原因"`
+	SuspendedUntil  *time.Time     `json:"suspended_until,omitempty" gorm:"comment:暂停截止时间,为空表示无限期"`
+	TOTPSecret      string         `json:"-" gorm:"column:totp_secret;size:64;comment:两步验证TOTP密钥"`
+	TOTPEnabled     bool           `json:"totp_enabled" gorm:"column:totp_enabled;default:false;comment:是否开启两步验证"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"comment:创建时间"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"comment:更新时间"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index;comment:删除时间"`
 
 	// 关联
 	Articles []Article `json:"articles,omitempty" gorm:"foreignKey:AuthorID"`
@@ -1,27 +1,49 @@
 package req
 
+import "time"
+
 type ArticleCreateRequest struct {
-	Title      string `json:"title" binding:"required,max=200"`
-	Content    string `json:"content" binding:"required"`
-	Summary    string `json:"summary" binding:"max=500"`
-	CoverImage string `json:"cover_image" binding:"max=255"`
-	Status     int    `json:"status" binding:"oneof=0 1"`
-	Tags       string `json:"tags" binding:"max=255"`
+	Title      string     `json:"title" binding:"required,max=200"`
+	Slug       string     `json:"slug" binding:"max=200"`
+	Content    string     `json:"content" binding:"required"`
+	Summary    string     `json:"summary" binding:"max=500"`
+	CoverImage string     `json:"cover_image" binding:"max=255"`
+	Status     int        `json:"status" binding:"oneof=0 1 2"`
+	PublishAt  *time.Time `json:"publish_at"`
+	Tags       string     `json:"tags" binding:"max=255"`
+	CategoryID uint       `json:"category_id"`
 }
 
 type ArticleUpdateRequest struct {
-	Title      string `json:"title" binding:"max=200"`
-	Content    string `json:"content"`
-	Summary    string `json:"summary" binding:"max=500"`
-	CoverImage string `json:"cover_image" binding:"max=255"`
-	Status     int    `json:"status" binding:"oneof=0 1"`
-	Tags       string `json:"tags" binding:"max=255"`
+	Title      string     `json:"title" binding:"max=200"`
+	Slug       string     `json:"slug" binding:"max=200"`
+	Content    string     `json:"content"`
+	Summary    string     `json:"summary" binding:"max=500"`
+	CoverImage string     `json:"cover_image" binding:"max=255"`
+	Status     int        `json:"status" binding:"oneof=0 1 2"`
+	PublishAt  *time.Time `json:"publish_at"`
+	Tags       string     `json:"tags" binding:"max=255"`
+	CategoryID uint       `json:"category_id"`
 }
 
 type ArticleListRequest struct {
 	Page     int    `form:"page" binding:"min=1"`
 	PageSize int    `form:"page_size" binding:"min=1,max=100"`
-	Status   int    `form:"status" binding:"oneof=0 1"`
+	Status   int    `form:"status" binding:"oneof=0 1 2"`
 	AuthorID uint   `form:"author_id"`
 	Keyword  string `form:"keyword"`
+	Tag      string `form:"tag"`
+	Category string `form:"category"`
+	Format   string `form:"format" binding:"omitempty,oneof=raw html"`
+}
+
+// ArticleFormatRequest 文章正文返回格式，raw返回Markdown原文，html返回渲染后的HTML、目录和阅读时长
+type ArticleFormatRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=raw html"`
+}
+
+type ArticleSearchRequest struct {
+	Keyword  string `form:"keyword" binding:"required,max=100"`
+	Page     int    `form:"page" binding:"min=1"`
+	PageSize int    `form:"page_size" binding:"min=1,max=100"`
 }
@@ -0,0 +1,15 @@
+package req
+
+type CategoryCreateRequest struct {
+	Name        string `json:"name" binding:"required,max=50"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+type CategoryUpdateRequest struct {
+	Name        string `json:"name" binding:"required,max=50"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+type CategoryMergeRequest struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
@@ -0,0 +1,15 @@
+package req
+
+type CommentCreateRequest struct {
+	Content  string `json:"content" binding:"required,max=1000"`
+	ParentID uint   `json:"parent_id"`
+}
+
+type CommentUpdateRequest struct {
+	Content string `json:"content" binding:"required,max=1000"`
+}
+
+type CommentListRequest struct {
+	Page     int `form:"page" binding:"min=1"`
+	PageSize int `form:"page_size" binding:"min=1,max=100"`
+}
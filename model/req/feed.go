@@ -0,0 +1,6 @@
+package req
+
+type FeedRequest struct {
+	AuthorID uint   `form:"author_id"`
+	Tag      string `form:"tag" binding:"max=50"`
+}
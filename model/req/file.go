@@ -0,0 +1,16 @@
+package req
+
+// FileListRequest 文件列表查询参数
+type FileListRequest struct {
+	Page       int    `form:"page" binding:"min=1"`
+	PageSize   int    `form:"page_size" binding:"min=1,max=100"`
+	Type       string `form:"type"`                                               // MIME类型，如image/png；不含斜杠时按大类筛选，如image
+	StartDate  string `form:"start_date" binding:"omitempty,datetime=2006-01-02"` // 上传日期起始(含)
+	EndDate    string `form:"end_date" binding:"omitempty,datetime=2006-01-02"`   // 上传日期截止(含)
+	UploaderID uint   `form:"uploader_id"`                                        // 上传者ID，仅管理员查询全部文件时有效
+}
+
+// FileRenameRequest 修改文件显示名称
+type FileRenameRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
@@ -0,0 +1,6 @@
+package req
+
+type RevisionDiffRequest struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to" binding:"required,min=1"`
+}
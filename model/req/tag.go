@@ -0,0 +1,13 @@
+package req
+
+type TagCreateRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+type TagUpdateRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+type TagMergeRequest struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
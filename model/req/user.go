@@ -1,5 +1,7 @@
 package req
 
+import "time"
+
 type UserRegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email"`
@@ -10,6 +12,50 @@ type UserRegisterRequest struct {
 type UserLoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+
+	// 登录设备信息，由handler填充
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type TokenRefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+
+	// 刷新时的客户端IP，由handler填充
+	IP string `json:"-"`
+}
+
+type UserRoleUpdateRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin editor author reader"`
+}
+
+type UserDisableRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+type UserSuspendRequest struct {
+	Reason string     `json:"reason" binding:"max=255"`
+	Until  *time.Time `json:"until"` // 暂停截止时间，为空表示无限期暂停
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"` // 强度由密码策略校验
+}
+
+type PasswordChangeRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"` // 强度由密码策略校验
+
+	RevokeAccessTokens bool `json:"revoke_access_tokens"` // 是否同时删除所有个人访问令牌
 }
 
 type UserUpdateProfileRequest struct {
@@ -18,3 +64,39 @@ type UserUpdateProfileRequest struct {
 	Bio      string `json:"bio" binding:"max=500"`
 	Email    string `json:"email" binding:"max=20"`
 }
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"` // 验证器App生成的6位验证码
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // 验证码或恢复码
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"` // 登录接口返回的两步验证令牌
+	Code           string `json:"code" binding:"required"`            // 验证码或恢复码
+
+	// 登录设备信息，由handler填充
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type AccessTokenCreateRequest struct {
+	Name      string     `json:"name" binding:"required,max=50"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"` // 授权范围，不能超出当前角色的权限
+	ExpiresAt *time.Time `json:"expires_at"`                      // 过期时间，为空表示永不过期
+}
+
+type OAuthLoginRequest struct {
+	Code  string `json:"code" binding:"required"`  // 第三方平台回调返回的授权码
+	State string `json:"state" binding:"required"` // 获取授权地址时返回的state
+
+	// 登录设备信息，由handler填充
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+
+	// 获取授权地址时写入浏览器cookie的nonce，由handler填充
+	Nonce string `json:"-"`
+}
@@ -9,6 +9,20 @@ type ArticleListResponse struct {
 	PageSize int              `json:"page_size"`
 }
 
+type ArticleSearchResult struct {
+	Article        *entity.Article `json:"article"`
+	Score          float64         `json:"score"`
+	TitleHighlight string          `json:"title_highlight"`
+	Snippet        string          `json:"snippet"`
+}
+
+type ArticleSearchResponse struct {
+	Results  []*ArticleSearchResult `json:"results"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+}
+
 // ToArticleResponse 转换为响应格式，隐藏敏感信息
 func ToArticleResponse(a *entity.Article) *entity.Article {
 	// 隐藏敏感信息
@@ -18,3 +32,9 @@ func ToArticleResponse(a *entity.Article) *entity.Article {
 	}
 	return a
 }
+
+type ArticleLikeResponse struct {
+	ArticleID uint `json:"article_id"`
+	Liked     bool `json:"liked"`
+	LikeCount int  `json:"like_count"`
+}
@@ -0,0 +1,8 @@
+package resp
+
+type CategoryResponse struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	ArticleCount int64  `json:"article_count"`
+}
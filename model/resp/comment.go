@@ -0,0 +1,22 @@
+package resp
+
+import "blog/model/entity"
+
+type CommentListResponse struct {
+	Comments []*entity.Comment `json:"comments"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// ToCommentResponse 转换为响应格式，隐藏敏感信息
+func ToCommentResponse(c *entity.Comment) *entity.Comment {
+	if c.User != nil {
+		c.User.Password = ""
+		c.User.Email = ""
+	}
+	for _, child := range c.Children {
+		ToCommentResponse(child)
+	}
+	return c
+}
@@ -0,0 +1,224 @@
+package resp
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// Feed 订阅源通用数据，可转换为RSS、Atom和JSON Feed格式
+type Feed struct {
+	Title       string
+	Link        string
+	FeedURL     string
+	Description string
+	Updated     time.Time
+	Items       []*FeedItem
+}
+
+type FeedItem struct {
+	ID          uint
+	Title       string
+	Link        string
+	Summary     string
+	Content     string
+	Author      string
+	Tags        []string
+	PublishedAt time.Time
+	UpdatedAt   time.Time
+}
+
+// RSS 2.0
+type RSS struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel RSSChannel `xml:"channel"`
+}
+
+type RSSChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	AtomLink      RSSLink    `xml:"atom:link"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	Items         []*RSSItem `xml:"item"`
+}
+
+type RSSLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type RSSItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        RSSGUID  `xml:"guid"`
+	Description string   `xml:"description"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+// Atom 1.0
+type Atom struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Links   []AtomLink   `xml:"link"`
+	Updated string       `xml:"updated"`
+	Entries []*AtomEntry `xml:"entry"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type AtomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       AtomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary"`
+	Author     *AtomAuthor    `xml:"author,omitempty"`
+	Categories []AtomCategory `xml:"category"`
+}
+
+type AtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type AtomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// JSONFeed JSON Feed 1.1
+type JSONFeed struct {
+	Version     string          `json:"version"`
+	Title       string          `json:"title"`
+	HomePageURL string          `json:"home_page_url"`
+	FeedURL     string          `json:"feed_url"`
+	Description string          `json:"description,omitempty"`
+	Items       []*JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string            `json:"id"`
+	URL           string            `json:"url"`
+	Title         string            `json:"title"`
+	ContentText   string            `json:"content_text"`
+	Summary       string            `json:"summary,omitempty"`
+	DatePublished string            `json:"date_published"`
+	DateModified  string            `json:"date_modified"`
+	Authors       []*JSONFeedAuthor `json:"authors,omitempty"`
+	Tags          []string          `json:"tags,omitempty"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// RSSGUID 文章的唯一标识，使用与链接无关的稳定ID，修改slug后阅读器不会重复推送
+type RSSGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// entryID 文章在订阅源中的唯一标识
+func entryID(item *FeedItem) string {
+	return fmt.Sprintf("urn:blog:article:%d", item.ID)
+}
+
+// ToRSS 转换为RSS 2.0格式
+func ToRSS(f *Feed) *RSS {
+	channel := RSSChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		AtomLink:      RSSLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+		Description:   f.Description,
+		LastBuildDate: f.Updated.Format(time.RFC1123Z),
+		Items:         make([]*RSSItem, 0, len(f.Items)),
+	}
+	for _, item := range f.Items {
+		channel.Items = append(channel.Items, &RSSItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        RSSGUID{Value: entryID(item)},
+			Description: item.Summary,
+			Creator:     item.Author,
+			Categories:  item.Tags,
+			PubDate:     item.PublishedAt.Format(time.RFC1123Z),
+		})
+	}
+	return &RSS{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: channel,
+	}
+}
+
+// ToAtom 转换为Atom 1.0格式
+func ToAtom(f *Feed) *Atom {
+	atom := &Atom{
+		Title: f.Title,
+		ID:    f.FeedURL,
+		Links: []AtomLink{
+			{Href: f.Link},
+			{Href: f.FeedURL, Rel: "self"},
+		},
+		Updated: f.Updated.Format(time.RFC3339),
+		Entries: make([]*AtomEntry, 0, len(f.Items)),
+	}
+	for _, item := range f.Items {
+		entry := &AtomEntry{
+			Title:     item.Title,
+			ID:        entryID(item),
+			Link:      AtomLink{Href: item.Link},
+			Published: item.PublishedAt.Format(time.RFC3339),
+			Updated:   item.UpdatedAt.Format(time.RFC3339),
+			Summary:   item.Summary,
+		}
+		if item.Author != "" {
+			entry.Author = &AtomAuthor{Name: item.Author}
+		}
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, AtomCategory{Term: tag})
+		}
+		atom.Entries = append(atom.Entries, entry)
+	}
+	return atom
+}
+
+// ToJSONFeed 转换为JSON Feed 1.1格式
+func ToJSONFeed(f *Feed) *JSONFeed {
+	feed := &JSONFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       make([]*JSONFeedItem, 0, len(f.Items)),
+	}
+	for _, item := range f.Items {
+		jsonItem := &JSONFeedItem{
+			ID:            fmt.Sprintf("%d", item.ID),
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentText:   item.Content,
+			Summary:       item.Summary,
+			DatePublished: item.PublishedAt.Format(time.RFC3339),
+			DateModified:  item.UpdatedAt.Format(time.RFC3339),
+			Tags:          item.Tags,
+		}
+		if item.Author != "" {
+			jsonItem.Authors = []*JSONFeedAuthor{{Name: item.Author}}
+		}
+		feed.Items = append(feed.Items, jsonItem)
+	}
+	return feed
+}
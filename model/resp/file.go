@@ -1,5 +1,7 @@
 package resp
 
+import "blog/model/entity"
+
 type FileUploadResponse struct {
 	ID       uint   `json:"id"`
 	FileName string `json:"file_name"`
@@ -7,3 +9,10 @@ type FileUploadResponse struct {
 	FileSize int64  `json:"file_size"`
 	FileType string `json:"file_type"`
 }
+
+type FileListResponse struct {
+	Files    []entity.File `json:"files"`
+	Total    int64         `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
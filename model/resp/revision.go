@@ -0,0 +1,12 @@
+package resp
+
+import "blog/internal/utils"
+
+type RevisionDiffResponse struct {
+	ArticleID uint             `json:"article_id"`
+	From      int              `json:"from"`
+	To        int              `json:"to"`
+	FromTitle string           `json:"from_title"`
+	ToTitle   string           `json:"to_title"`
+	Lines     []utils.DiffLine `json:"lines"`
+}
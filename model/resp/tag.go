@@ -0,0 +1,7 @@
+package resp
+
+type TagResponse struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	ArticleCount int64  `json:"article_count"`
+}
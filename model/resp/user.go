@@ -1,29 +1,104 @@
 package resp
 
-import "blog/model/entity"
+import (
+	"time"
+
+	"blog/internal/rbac"
+	"blog/model/entity"
+)
+
+type TokenResponse struct {
+	Token        string `json:"token"`         // access token
+	RefreshToken string `json:"refresh_token"` // 用于换取新的access token，使用后即作废
+	ExpiresIn    int64  `json:"expires_in"`    // access token有效期(秒)
+}
 
 type UserLoginResponse struct {
-	Token string       `json:"token"`
-	User  *entity.User `json:"user"`
+	*TokenResponse
+	User *entity.User `json:"user,omitempty"`
+
+	// 开启两步验证时不签发token，需携带challenge_token调用两步验证登录接口
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
 }
 
 type UserProfileResponse struct {
-	ID       uint   `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Nickname string `json:"nickname"`
-	Avatar   string `json:"avatar"`
-	Bio      string `json:"bio"`
+	ID          uint              `json:"id"`
+	Username    string            `json:"username"`
+	Email       string            `json:"email"`
+	Nickname    string            `json:"nickname"`
+	Avatar      string            `json:"avatar"`
+	Bio         string            `json:"bio"`
+	Role        string            `json:"role"`
+	Permissions []rbac.Permission `json:"permissions"`
 }
 
 // ToUserProfileResponse 转换为用户资料响应格式
 func ToUserProfileResponse(u *entity.User) *UserProfileResponse {
 	return &UserProfileResponse{
-		ID:       u.ID,
-		Username: u.Username,
-		Email:    u.Email,
-		Nickname: u.Nickname,
-		Avatar:   u.Avatar,
-		Bio:      u.Bio,
+		ID:          u.ID,
+		Username:    u.Username,
+		Email:       u.Email,
+		Nickname:    u.Nickname,
+		Avatar:      u.Avatar,
+		Bio:         u.Bio,
+		Role:        u.Role,
+		Permissions: rbac.Permissions(u.Role),
 	}
 }
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"` // 是否为当前请求所用的会话
+}
+
+type SessionRevokeResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`      // Base32编码的TOTP密钥，用于手动输入
+	OTPAuthURI string `json:"otpauth_uri"` // 供验证器App扫码的链接
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"` // 恢复码仅显示一次，每个只能使用一次
+}
+
+type AccessTokenResponse struct {
+	ID         uint              `json:"id"`
+	Name       string            `json:"name"`
+	Prefix     string            `json:"prefix"` // 令牌前几位，用于识别令牌
+	Scopes     []rbac.Permission `json:"scopes"`
+	ExpiresAt  *time.Time        `json:"expires_at"`
+	LastUsedAt *time.Time        `json:"last_used_at"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+type AccessTokenCreateResponse struct {
+	AccessTokenResponse
+	Token string `json:"token"` // 完整令牌仅在创建时返回一次
+}
+
+// ToAccessTokenResponse 转换为访问令牌响应格式
+func ToAccessTokenResponse(t *entity.AccessToken) *AccessTokenResponse {
+	return &AccessTokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     rbac.ParseScopes(t.Scopes),
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+type OAuthAuthorizeResponse struct {
+	AuthorizeURL string `json:"authorize_url"` // 第三方授权页面地址
+	State        string `json:"state"`         // 防CSRF的随机值，回调时需原样提交
+	Nonce        string `json:"-"`             // 绑定发起授权的浏览器，由handler写入HttpOnly cookie
+}